@@ -6,6 +6,12 @@ REBUILD_ONLY=0
 JSON_OUTPUT=0
 DOCKER_MODE=0
 DOCKER_IMAGE="codestylechecker"
+CONFIG=""
+FIX=0
+METRICS=""
+REPORT=""
+INFER=0
+STD=""
 
 IN_CONTAINER=0
 if [[ -x "/app/bin/check_style" ]]; then
@@ -15,12 +21,18 @@ fi
 print_usage() {
   cat <<EOF
 Usage:
-  $0 [options] <style: kr|allman> <file.c|directory>
+  $0 [options] <style: kr|allman|stroustrup|linux|gnu|whitesmiths|auto> <file.c|directory>
 Options:
   -h, --help            Show this help message and exit
   -v, --verbose         Enable verbose output
   -r, --rebuild-only    Only (re)build the Go binary; do not run checks
   -j, --json            Emit pretty JSON of each error (written to ./out/errors_<style>_<date>_<time>.json)
+  -c, --config <file>   Load rule settings (naming conventions, ...) from a JSON config file
+  --std <std>           C standard to check against (c89|c99|c11|c17|c23, default c17)
+  --fix                 Insert or update the file banner before checking
+  --report summary      Print findings per rule/file/directory and line counts instead of each finding
+  -m, --metrics <fmt>   Write per-function metrics (json|csv) to ./out/metrics_<date>_<time>.<fmt> instead of checking
+  --infer-config        Write a config inferred from the files to ./out/config_<date>_<time>.json instead of checking
   --docker              Run the analysis inside a Docker container (mounting the target file/dir into /work)
 EOF
 }
@@ -32,6 +44,16 @@ while [[ $# -gt 0 && "$1" == -* ]]; do
     -v|--verbose)      VERBOSE=1; shift ;;
     -r|--rebuild-only) REBUILD_ONLY=1; shift ;;
     -j|--json)         JSON_OUTPUT=1; shift ;;
+    -c|--config)       CONFIG="${2:-}"; shift 2 ;;
+    --config=*)        CONFIG="${1#*=}"; shift ;;
+    --fix)             FIX=1; shift ;;
+    --report)          REPORT="${2:-}"; shift 2 ;;
+    --report=*)        REPORT="${1#*=}"; shift ;;
+    -m|--metrics)      METRICS="${2:-}"; shift 2 ;;
+    --metrics=*)       METRICS="${1#*=}"; shift ;;
+    --infer-config)    INFER=1; shift ;;
+    --std)             STD="${2:-}"; shift 2 ;;
+    --std=*)           STD="${1#*=}"; shift ;;
     --docker)          DOCKER_MODE=1; shift ;;
     --)                shift; break ;;
     *) echo "Unknown option: $1" >&2; print_usage; exit 1 ;;
@@ -46,8 +68,28 @@ fi
 
 STYLE="$1"; TARGET="$2"
 
-if [[ "$STYLE" != "kr" && "$STYLE" != "allman" ]]; then
-  echo "Error: style must be 'kr' or 'allman'" >&2
+case "$STYLE" in
+  kr|allman|stroustrup|linux|gnu|whitesmiths|auto) ;;
+  *) echo "Error: style must be 'kr', 'allman', 'stroustrup', 'linux', 'gnu', 'whitesmiths' or 'auto'" >&2; exit 1 ;;
+esac
+
+if [[ -n "$METRICS" && "$METRICS" != "json" && "$METRICS" != "csv" ]]; then
+  echo "Error: metrics format must be 'json' or 'csv'" >&2
+  exit 1
+fi
+
+case "$STD" in
+  ""|c89|c90|c99|c11|c17|c18|c23) ;;
+  *) echo "Error: std must be 'c89', 'c99', 'c11', 'c17' or 'c23'" >&2; exit 1 ;;
+esac
+
+if [[ -n "$REPORT" && "$REPORT" != "summary" ]]; then
+  echo "Error: report must be 'summary'" >&2
+  exit 1
+fi
+
+if [[ -n "$CONFIG" && ! -f "$CONFIG" ]]; then
+  echo "Error: config file '$CONFIG' not found" >&2
   exit 1
 fi
 
@@ -64,8 +106,12 @@ if (( DOCKER_MODE )) && (( IN_CONTAINER == 0 )); then
     TARGET_ABS="$(readlink -f "$TARGET")"
   fi
 
-  DOCKER_VOLUMES=(-v "$TARGET_ABS:/work:ro")
-  if (( JSON_OUTPUT )); then
+  if (( FIX )); then
+    DOCKER_VOLUMES=(-v "$TARGET_ABS:/work")
+  else
+    DOCKER_VOLUMES=(-v "$TARGET_ABS:/work:ro")
+  fi
+  if (( JSON_OUTPUT || INFER )) || [[ -n "$METRICS" ]]; then
     mkdir -p out
     DOCKER_VOLUMES+=(-v "$(pwd)/out:/app/out")
   fi
@@ -73,6 +119,20 @@ if (( DOCKER_MODE )) && (( IN_CONTAINER == 0 )); then
   CMD_ARGS=()
   (( VERBOSE )) && CMD_ARGS+=("-v")
   (( JSON_OUTPUT )) && CMD_ARGS+=("-j")
+  (( FIX )) && CMD_ARGS+=("--fix")
+  [[ -n "$METRICS" ]] && CMD_ARGS+=("--metrics" "$METRICS")
+  (( INFER )) && CMD_ARGS+=("--infer-config")
+  [[ -n "$STD" ]] && CMD_ARGS+=("--std" "$STD")
+  [[ -n "$REPORT" ]] && CMD_ARGS+=("--report" "$REPORT")
+  if [[ -n "$CONFIG" ]]; then
+    if command -v realpath >/dev/null 2>&1; then
+      CONFIG_ABS="$(realpath "$CONFIG")"
+    else
+      CONFIG_ABS="$(readlink -f "$CONFIG")"
+    fi
+    DOCKER_VOLUMES+=(-v "$CONFIG_ABS:/app/config.json:ro")
+    CMD_ARGS+=("--config" "/app/config.json")
+  fi
 
   docker run --rm \
     "${DOCKER_VOLUMES[@]}" \
@@ -101,9 +161,15 @@ else
   fi
 fi
 
+# ----------------------- Checker arguments -----------------------
+CHECK_ARGS=(--style="$STYLE")
+[[ -n "$CONFIG" ]] && CHECK_ARGS+=(--config="$CONFIG")
+[[ -n "$STD" ]] && CHECK_ARGS+=(--std="$STD")
+(( FIX )) && CHECK_ARGS+=(--fix)
+
 # ----------------------- Collect files -----------------------
 if [[ -d "$TARGET" ]]; then
-  mapfile -t files < <(find "$TARGET" -type f -name '*.c')
+  mapfile -t files < <(find "$TARGET" -type f \( -name '*.c' -o -name '*.h' \))
 elif [[ -f "$TARGET" ]]; then
   files=("$TARGET")
 else
@@ -111,6 +177,31 @@ else
   exit 1
 fi
 
+# ----------------------- Metrics report -----------------------
+if [[ -n "$METRICS" ]]; then
+  mkdir -p out
+  OUT="./out/metrics_$(date +"%Y%m%d")_$(date +"%H%M%S").$METRICS"
+  "$BIN" --metrics="$METRICS" "${files[@]}" > "$OUT"
+  echo "Written function metrics to $OUT"
+  exit 0
+fi
+
+# ----------------------- Inferred config -----------------------
+if (( INFER )); then
+  mkdir -p out
+  OUT="./out/config_$(date +"%Y%m%d")_$(date +"%H%M%S").json"
+  "$BIN" --infer-config "${files[@]}" > "$OUT"
+  echo "Written inferred config to $OUT"
+  exit 0
+fi
+
+# ----------------------- Normal output -----------------------
+if (( ! JSON_OUTPUT )); then
+  (( VERBOSE )) && printf 'Checking %s...\n' "${files[@]}"
+  [[ -n "$REPORT" ]] && CHECK_ARGS+=(--report="$REPORT")
+  exec "$BIN" "${CHECK_ARGS[@]}" "${files[@]}"
+fi
+
 # ----------------------- JSON output init -----------------------
 if (( JSON_OUTPUT )); then
   mkdir -p out
@@ -124,7 +215,7 @@ fi
 # ----------------------- Process files -----------------------
 for file in "${files[@]}"; do
   (( VERBOSE )) && echo "Checking $file..."
-  raw="$("$BIN" --style="$STYLE" "$file" 2>&1 || true)"
+  raw="$("$BIN" "${CHECK_ARGS[@]}" "$file" 2>&1 || true)"
   cleaned=$(printf '%s\n' "$raw" | sed -r 's/\x1B\[[0-9;]*[mK]//g;1,/^[-]{5,}$/d')
 
   state=0
@@ -173,10 +264,3 @@ if (( JSON_OUTPUT )); then
   echo "Written pretty JSON errors to $OUT"
   exit 0
 fi
-
-# ----------------------- Normal output -----------------------
-fail=0
-for file in "${files[@]}"; do
-  "$BIN" --style="$STYLE" "$file" || fail=1
-done
-exit $fail
@@ -6,15 +6,20 @@ package main
 import (
     "bufio"
     "bytes"
+    "encoding/csv"
+    "encoding/json"
     "errors"
     "flag"
     "fmt"
     "io"
+    "math"
     "os"
     "path/filepath"
     "regexp"
     "sort"
+    "strconv"
     "strings"
+    "time"
     "unicode"
     "unicode/utf8"
 )
@@ -33,6 +38,13 @@ type StyleError struct {
     Length  int
     Message string
     Level   string
+    Code    ErrorCode
+}
+
+type indentFrame struct {
+    Col   int
+    Owner string
+    Line  int
 }
 
 type typeCtx struct {
@@ -49,13 +61,343 @@ type FileContext struct {
     Lines    []string
     Raw      []byte
     Style    StyleMode
+    Config   *Config
+    Source   *sourceModel
     Errors   []StyleError
 }
 
+type NamingRule struct {
+    Case         string   `json:"case"`
+    Prefix       string   `json:"prefix"`
+    Suffix       string   `json:"suffix"`
+    ForbidSuffix string   `json:"forbid_suffix"`
+    MinLength    int      `json:"min_length"`
+    MaxLength    int      `json:"max_length"`
+    Exclude      []string `json:"exclude"`
+}
+
+type ModuleConfig struct {
+    Enabled bool              `json:"enabled"`
+    Source  string            `json:"source"`
+    Map     map[string]string `json:"map"`
+}
+
+type NamingConfig struct {
+    Module        ModuleConfig `json:"module"`
+    Function      NamingRule   `json:"function"`
+    StaticFunc    NamingRule   `json:"static_function"`
+    Global        NamingRule   `json:"global"`
+    StaticGlobal  NamingRule   `json:"static_global"`
+    Local         NamingRule   `json:"local"`
+    Parameter     NamingRule   `json:"parameter"`
+    Macro         NamingRule   `json:"macro"`
+    FunctionMacro NamingRule   `json:"function_macro"`
+    Typedef       NamingRule   `json:"typedef"`
+    StructTag     NamingRule   `json:"struct_tag"`
+    EnumTag       NamingRule   `json:"enum_tag"`
+    Enumerator    NamingRule   `json:"enumerator"`
+    Field         NamingRule   `json:"field"`
+    Label         NamingRule   `json:"label"`
+}
+
+type APIConfig struct {
+    Enabled    bool     `json:"enabled"`
+    HeaderDirs []string `json:"header_dirs"`
+    SourceDirs []string `json:"source_dirs"`
+}
+
+type HeaderConfig struct {
+    Enabled         bool     `json:"enabled"`
+    RequireExternC  bool     `json:"require_extern_c"`
+    PrivatePatterns []string `json:"private_patterns"`
+}
+
+type DoxygenConfig struct {
+    Enabled   bool     `json:"enabled"`
+    ExtraTags []string `json:"extra_tags"`
+}
+
+type BannerConfig struct {
+    Enabled  bool     `json:"enabled"`
+    Template []string `json:"template"`
+    Licenses []string `json:"licenses"`
+    Holder   string   `json:"holder"`
+}
+
+type MetricsConfig struct {
+    Enabled       bool `json:"enabled"`
+    MaxComplexity int  `json:"max_complexity"`
+    MaxNesting    int  `json:"max_nesting"`
+    MaxStatements int  `json:"max_statements"`
+    MaxLines      int  `json:"max_lines"`
+    MaxParams     int  `json:"max_params"`
+    MaxReturns    int  `json:"max_returns"`
+}
+
+type IndentConfig struct {
+    Style    string `json:"style"`
+    Width    int    `json:"width"`
+    TabWidth int    `json:"tab_width"`
+
+    IndentCaseLabels bool `json:"indent_case_labels"`
+    AlignBackslashes bool `json:"align_backslashes"`
+}
+
+type WrapConfig struct {
+    Enabled            bool   `json:"enabled"`
+    Continuation       string `json:"continuation"`
+    ContinuationIndent int    `json:"continuation_indent"`
+    OperatorBreak      string `json:"operator_break"`
+    OneArgPerLine      bool   `json:"one_arg_per_line"`
+}
+
+type LineLengthConfig struct {
+    Code           int  `json:"code"`
+    Comment        int  `json:"comment"`
+    Soft           int  `json:"soft"`
+    ExemptURLs     bool `json:"exempt_urls"`
+    ExemptIncludes bool `json:"exempt_includes"`
+    ExemptStrings  bool `json:"exempt_strings"`
+}
+
+type MisraConfig struct {
+    Enabled          bool     `json:"enabled"`
+    Deviations       []string `json:"deviations"`
+    SignificantChars int      `json:"significant_chars"`
+}
+
+type PowerOfTenConfig struct {
+    NoGoto            bool     `json:"no_goto"`
+    NoRecursion       bool     `json:"no_recursion"`
+    BoundedLoops      bool     `json:"bounded_loops"`
+    NoDynamicAlloc    bool     `json:"no_dynamic_alloc"`
+    InitFunctions     []string `json:"init_functions"`
+    FunctionLength    bool     `json:"function_length"`
+    MaxFunctionLines  int      `json:"max_function_lines"`
+    AssertionDensity  bool     `json:"assertion_density"`
+    MinAssertions     int      `json:"min_assertions"`
+    AssertMacros      []string `json:"assert_macros"`
+    CheckReturnValues bool     `json:"check_return_values"`
+}
+
+type UnicodeConfig struct {
+    AllowInComments bool `json:"allow_in_comments"`
+    AllowInStrings  bool `json:"allow_in_strings"`
+}
+
+type SecretsConfig struct {
+    Enabled          bool              `json:"enabled"`
+    Patterns         map[string]string `json:"patterns"`
+    Disable          []string          `json:"disable"`
+    Allowlist        []string          `json:"allowlist"`
+    EntropyThreshold float64           `json:"entropy_threshold"`
+    EntropyMinLength int               `json:"entropy_min_length"`
+    WarnInComments   bool              `json:"warn_in_comments"`
+}
+
+type SwitchConfig struct {
+    RequireDefault          bool `json:"require_default"`
+    DefaultFirstOrLast      bool `json:"default_first_or_last"`
+    NoEmpty                 bool `json:"no_empty"`
+    NoSingleCase            bool `json:"no_single_case"`
+    EnumCoverage            bool `json:"enum_coverage"`
+    EnumCoverageWithDefault bool `json:"enum_coverage_with_default"`
+}
+
+type MagicNumberConfig struct {
+    Enabled          bool     `json:"enabled"`
+    Allow            []string `json:"allow"`
+    AllowPowersOfTwo bool     `json:"allow_powers_of_two"`
+    AllowShiftCounts bool     `json:"allow_shift_counts"`
+}
+
+type CertConfig struct {
+    Enabled    bool     `json:"enabled"`
+    Deviations []string `json:"deviations"`
+}
+
+type PointerConfig struct {
+    Style string `json:"style"`
+}
+
+type Config struct {
+    Naming       NamingConfig               `json:"naming"`
+    API          APIConfig                  `json:"api"`
+    Header       HeaderConfig               `json:"header"`
+    Doxygen      DoxygenConfig              `json:"doxygen"`
+    Banner       BannerConfig               `json:"banner"`
+    Metrics      MetricsConfig              `json:"metrics"`
+    Indent       IndentConfig               `json:"indent"`
+    Wrap         WrapConfig                 `json:"wrap"`
+    Pointer      PointerConfig              `json:"pointer"`
+    Std          string                     `json:"std"`
+    Misra        MisraConfig                `json:"misra"`
+    PowerOfTen   PowerOfTenConfig           `json:"power_of_ten"`
+    Cert         CertConfig                 `json:"cert"`
+    Unicode      UnicodeConfig              `json:"unicode"`
+    Secrets      SecretsConfig              `json:"secrets"`
+    Switch       SwitchConfig               `json:"switch"`
+    MagicNumbers MagicNumberConfig          `json:"magic_numbers"`
+    LineLength   LineLengthConfig           `json:"line_length"`
+    Inferred     map[string]inferredSetting `json:"inferred,omitempty"`
+}
+
+type cToken struct {
+    Kind tokenKind
+    Text string
+    Line int
+    Col  int
+}
+
+type cDirective struct {
+    Line    int
+    EndLine int
+    Text    string
+    Tokens  []cToken
+}
+
+type cComment struct {
+    Line    int
+    EndLine int
+    Col     int
+    Text    string
+    Block   bool
+}
+
+type wrapBreak struct {
+    Tok       int
+    Open      int
+    StmtStart int
+}
+
+type wrapCall struct {
+    Open   int
+    Close  int
+    Commas []int
+}
+
+type inferredSetting struct {
+    Value      string  `json:"value"`
+    Confidence float64 `json:"confidence"`
+    Samples    int     `json:"samples"`
+}
+
+type styleVotes map[string]map[string]int
+
+type styleInference struct {
+    Style    StyleMode
+    Settings map[string]inferredSetting
+}
+
+type fileSummary struct {
+    File     string
+    Code     int
+    Comment  int
+    Blank    int
+    Errors   int
+    Warnings int
+    Rules    map[ErrorCode]int
+}
+
+type funcMetrics struct {
+    File       string `json:"file"`
+    Function   string `json:"function"`
+    Line       int    `json:"line"`
+    Complexity int    `json:"complexity"`
+    Nesting    int    `json:"max_nesting"`
+    Statements int    `json:"statements"`
+    Lines      int    `json:"lines"`
+    Params     int    `json:"params"`
+    Returns    int    `json:"returns"`
+}
+
+type fileBanner struct {
+    Start     int
+    End       int
+    Text      []string
+    Copyright int
+    SPDX      int
+    Years     string
+    Holder    string
+    License   string
+    FirstYear int
+    LastYear  int
+}
+
+type docTag struct {
+    Name string
+    Arg  string
+    Line int
+    Col  int
+}
+
+type cParam struct {
+    Name string
+    Type string
+    Line int
+    Col  int
+}
+
+type cFunction struct {
+    Name       string
+    ReturnType string
+    Params     []cParam
+    Line       int
+    Col        int
+    StartLine  int
+    Static     bool
+    Inline     bool
+    IsDef      bool
+    BodyStart  int
+    BodyEnd    int
+}
+
+type cIdent struct {
+    Kind   EntityKind
+    Name   string
+    Line   int
+    Col    int
+    Extern bool
+}
+
+type cDeclarator struct {
+    name   cToken
+    prefix []cToken
+    params []cToken
+    isFunc bool
+}
+
+type parseScope struct {
+    kind    scopeKind
+    outer   []cToken
+    funcIdx int
+}
+
+type declParser struct {
+    model    *sourceModel
+    typedefs map[string]bool
+}
+
+type sourceModel struct {
+    Tokens     []cToken
+    Directives []cDirective
+    Comments   []cComment
+    Functions  []cFunction
+    Idents     []cIdent
+}
+
 type ErrorCode int
 
 type StyleMode int
 
+type LangStd int
+
+type EntityKind int
+
+type tokenKind int
+
+type scopeKind int
+
 /** ===============================================================
  *              C O N S T  D E F I N I T I O N S
  * ================================================================ */
@@ -65,12 +407,59 @@ const (
 )
 
 const (
-    maxLineLength = 80
+    maxLineLength   = 80
+    summaryTopFiles = 10
 )
 
 const (
     StyleKR StyleMode = iota
     StyleAllman
+    StyleStroustrup
+    StyleLinux
+    StyleGNU
+    StyleWhitesmiths
+)
+
+const (
+    StdC89 LangStd = iota
+    StdC99
+    StdC11
+    StdC17
+    StdC23
+)
+
+const (
+    KindFunction EntityKind = iota
+    KindStaticFunction
+    KindGlobal
+    KindStaticGlobal
+    KindLocal
+    KindParameter
+    KindMacro
+    KindFunctionMacro
+    KindTypedef
+    KindStructTag
+    KindEnumTag
+    KindEnumerator
+    KindField
+    KindLabel
+)
+
+const (
+    tokIdent tokenKind = iota
+    tokNumber
+    tokString
+    tokChar
+    tokPunct
+)
+
+const (
+    scopeFile scopeKind = iota
+    scopeExternC
+    scopeFunc
+    scopeBlock
+    scopeStruct
+    scopeEnum
 )
 
 /** ===============================================================
@@ -99,7 +488,8 @@ const (
     ErrNoSpaceAllowedAroundBrackets
     ErrCommaMustBeSurroundedBySingleSpace
     ErrMultipleConsecutiveSpaces
-    ErrPointerFormattingRules
+    ErrPointerStarStyle
+    ErrPointerMustBeAttached
     ErrPointerCastMustBeAttached
     ErrMacroBodyMustHaveSpaceAfterParams
     ErrMacroParamMustBeSnakeCase
@@ -109,11 +499,8 @@ const (
     ErrKeywordMustHaveSpaceBeforeParen
     WarnMagicNumberDetected
     ErrFuncNameNoSpaceBeforeParen
-    ErrFunctionNameMustBeModuleCamelCase
-    ErrParameterLineWrongIndent
     ErrParameterLineMustEndWithComma
     ErrLabelMustHaveNoIndentation
-    ErrLabelMustBeSnakeLowerCase
     ErrColonMustBeAttachedToToken
     ErrReturnTypeMustBeOnSameLineAsName
     ErrSpaceBeforeFuncCallParen
@@ -126,17 +513,9 @@ const (
     WarnCaseBlocksMustNotUseBraces
     WarnCaseBlockMissingBreakOrFallthrough
     ErrExpectedSpaceAfterClosingBrace
-    ErrInstanceMustBeSnakeLowerCase
-    ErrInstanceMustNotEndWithT
-    ErrTypeTagMustBeCamelCase
     WarnDeclaredWithoutInitialization
-    ErrVariableNameMustNotEndWithT
     ErrMultipleVariableDeclarationsNotAllowed
-    WarnTypedefFuncPtrNameMustBeSnakeLowerCaseAndEndWithT
-    WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT
-    ErrMacroNameMustBeScreamingSnakeCase
     ErrFunctionLikeMacroBodyMustBeParenthesized
-    ErrParameterNameMustBeSnakeLowerCase
     ErrTernaryQuestionMarkMustHaveSpaceBefore
     ErrTernaryQuestionMarkMustHaveSpaceAfter
     ErrTernaryColonMustHaveSpaceBefore
@@ -148,8 +527,100 @@ const (
     ErrClosingBraceMustBeOwnLine
     ErrAllocCallMustBeCast
     ErrExpectedSpaceAfterOpeningBrace
-    ErrEnumElementMustBeScreamingSnakeCase
-    ErrStructFieldMustBeSnakeLowerCase
+    WarnTypedefMissingName
+    ErrNameCaseStyle
+    ErrNameMissingPrefix
+    ErrNameMissingSuffix
+    ErrNameForbiddenSuffix
+    ErrNameTooShort
+    ErrNameTooLong
+    ErrNameMissingModulePrefix
+    ErrFunctionNotDeclaredInHeader
+    ErrPrototypeWithoutDefinition
+    ErrPrototypeReturnTypeMismatch
+    ErrPrototypeParamCountMismatch
+    ErrPrototypeParamNameMismatch
+    ErrPrototypeParamTypeMismatch
+    ErrOwnHeaderMustBeIncludedFirst
+    ErrHeaderVariableDefinition
+    ErrHeaderFunctionDefinition
+    ErrHeaderStaticDeclaration
+    ErrHeaderMissingExternC
+    ErrHeaderExternCNotGuarded
+    ErrHeaderDeclOutsideExternC
+    ErrDoxygenMissing
+    ErrDoxygenParamMismatch
+    ErrDoxygenMissingReturn
+    ErrDoxygenUnexpectedReturn
+    WarnDoxygenUnknownTag
+    ErrBannerMissing
+    ErrBannerMissingCopyright
+    ErrBannerInvalidYear
+    ErrBannerMissingSPDX
+    ErrBannerLicenseNotAllowed
+    ErrBannerTemplateMismatch
+    WarnFunctionTooComplex
+    WarnFunctionTooDeep
+    WarnFunctionTooManyStatements
+    WarnFunctionTooLong
+    WarnFunctionTooManyParams
+    WarnFunctionTooManyReturns
+    ErrIndentTabsNotAllowed
+    ErrIndentSpacesNotAllowed
+    ErrMixedIndentation
+    ErrBlockBodyIndent
+    ErrCaseLabelIndent
+    ErrContinuationIndent
+    ErrDirectiveIndent
+    ErrClosingBraceIndent
+    ErrOperatorAtLineStart
+    ErrOperatorAtLineEnd
+    ErrOneArgumentPerLine
+    ErrCommentLineLengthExceeded
+    WarnLineLengthSoftLimit
+    ErrElseMustBeOnOwnLine
+    ErrFeatureNotInStd
+    ErrMixedDeclaration
+    WarnObsolescentSpelling
+    ErrMisraCompoundBody
+    ErrMisraDefaultPosition
+    ErrMisraBackwardGoto
+    ErrMisraOctalConstant
+    ErrMisraUnsignedSuffix
+    WarnMisraFunctionMacro
+    WarnMisraSingleExit
+    ErrMisraIdentifierNotDistinct
+    ErrP10Goto
+    ErrP10Recursion
+    ErrP10UnboundedLoop
+    ErrP10DynamicAlloc
+    ErrP10FunctionTooLong
+    ErrP10AssertionDensity
+    ErrP10UncheckedReturn
+    ErrCertFormatString
+    ErrCertReallocLeak
+    ErrCertUncheckedAlloc
+    ErrCertSizeofPointer
+    WarnCertUncheckedConversion
+    ErrCertSignalHandlerCall
+    ErrBidiControlCharacter
+    ErrInvisibleCharacter
+    ErrHomoglyphIdentifier
+    ErrHardcodedSecret
+    WarnHighEntropySecret
+    ErrSwitchMissingDefault
+    ErrSwitchDefaultPosition
+    ErrSwitchEmpty
+    WarnSwitchSingleCase
+    WarnSwitchMissingEnumerators
+    ErrMacroParamNotParenthesized
+    ErrMacroNotDoWhile
+    ErrMacroTrailingSemicolon
+    WarnMacroParamEvaluatedTwice
+    ErrMacroUnbalancedParens
+    ErrMacroRedefined
+    ErrBackslashNotAligned
+    WarnSecretInComment
 
     NumErrorMessages
 )
@@ -224,7 +695,179 @@ var errorInfos = [NumErrorMessages]ErrorInfo{
     },
     ErrElseMustBeOnSameLineAsClosingBrace: {
         Level:   LevelError,
-        Message: `"else" must be on the same line as the closing '}' (K&R style)`,
+        Message: `"else" must be on the same line as the closing '}' (%s style)`,
+    },
+    ErrElseMustBeOnOwnLine: {
+        Level:   LevelError,
+        Message: `"else" must start its own line after the closing '}' (%s style)`,
+    },
+    ErrFeatureNotInStd: {
+        Level:   LevelError,
+        Message: "%s not available before %s (checking as %s)",
+    },
+    ErrMixedDeclaration: {
+        Level:   LevelError,
+        Message: "declaration of '%s' %s is not allowed in %s",
+    },
+    WarnObsolescentSpelling: {
+        Level:   LevelWarning,
+        Message: "'%s' is obsolescent in C23; use '%s'",
+    },
+    ErrMisraCompoundBody: {
+        Level:   LevelError,
+        Message: "MISRA C:2012 Rule 15.6: the body of '%s' must be a compound statement",
+    },
+    ErrMisraDefaultPosition: {
+        Level:   LevelError,
+        Message: "MISRA C:2012 Rule 16.5: 'default' must be the first or the last label of the switch",
+    },
+    ErrMisraBackwardGoto: {
+        Level:   LevelError,
+        Message: "MISRA C:2012 Rule 15.2: 'goto %s' jumps backwards to the label on line %d",
+    },
+    ErrMisraOctalConstant: {
+        Level:   LevelError,
+        Message: "MISRA C:2012 Rule 7.1: octal constant '%s' must not be used",
+    },
+    ErrMisraUnsignedSuffix: {
+        Level:   LevelError,
+        Message: "MISRA C:2012 Rule 7.2: unsigned constant '%s' must have a 'U' suffix",
+    },
+    WarnMisraFunctionMacro: {
+        Level:   LevelWarning,
+        Message: "MISRA C:2012 Dir 4.9: function-like macro '%s' should be a function",
+    },
+    WarnMisraSingleExit: {
+        Level:   LevelWarning,
+        Message: "MISRA C:2012 Rule 15.5: function '%s' should have a single point of exit at its end",
+    },
+    ErrMisraIdentifierNotDistinct: {
+        Level:   LevelError,
+        Message: "MISRA C:2012 Rule %s: %s '%s' is not distinct from '%s' (line %d) in its first %d characters",
+    },
+    ErrP10Goto: {
+        Level:   LevelError,
+        Message: "Power of Ten rule 1: '%s' must not be used",
+    },
+    ErrP10Recursion: {
+        Level:   LevelError,
+        Message: "Power of Ten rule 1: function '%s' is recursive (%s)",
+    },
+    ErrP10UnboundedLoop: {
+        Level:   LevelError,
+        Message: "Power of Ten rule 2: '%s' loop has no statically visible upper bound",
+    },
+    ErrP10DynamicAlloc: {
+        Level:   LevelError,
+        Message: "Power of Ten rule 3: '%s' must not be called after initialization (in '%s')",
+    },
+    ErrP10FunctionTooLong: {
+        Level:   LevelError,
+        Message: "Power of Ten rule 4: function '%s' has %d lines (max %d)",
+    },
+    ErrP10AssertionDensity: {
+        Level:   LevelError,
+        Message: "Power of Ten rule 5: function '%s' has %d assertion(s) (min %d)",
+    },
+    ErrP10UncheckedReturn: {
+        Level:   LevelError,
+        Message: "Power of Ten rule 7: return value of '%s' is not checked (cast the call to (void) to discard it)",
+    },
+    ErrCertFormatString: {
+        Level:   LevelError,
+        Message: "CERT FIO30-C: format string passed to '%s' is not a string literal",
+    },
+    ErrCertReallocLeak: {
+        Level:   LevelError,
+        Message: "CERT MEM31-C: '%s = realloc(%s, ...)' leaks the old block when realloc fails",
+    },
+    ErrCertUncheckedAlloc: {
+        Level:   LevelError,
+        Message: "CERT ERR33-C: result of '%s' is used without checking it for NULL",
+    },
+    ErrCertSizeofPointer: {
+        Level:   LevelError,
+        Message: "CERT ARR01-C: size passed to '%s' is the size of pointer '%s', not of the object it points to",
+    },
+    WarnCertUncheckedConversion: {
+        Level:   LevelWarning,
+        Message: "CERT ERR34-C: '%s' cannot report conversion errors, use '%s'",
+    },
+    ErrCertSignalHandlerCall: {
+        Level:   LevelError,
+        Message: "CERT SIG30-C: signal handler '%s' (registered on line %d) calls '%s', which is not async-signal-safe",
+    },
+    ErrBidiControlCharacter: {
+        Level:   LevelError,
+        Message: "bidirectional control character U+%04X (%s) can make the code read differently than it compiles",
+    },
+    ErrInvisibleCharacter: {
+        Level:   LevelError,
+        Message: "invisible character U+%04X (%s)",
+    },
+    ErrHomoglyphIdentifier: {
+        Level:   LevelError,
+        Message: "identifier '%s' contains '%c' (U+%04X), which looks like the ASCII letter '%c'",
+    },
+    ErrHardcodedSecret: {
+        Level:   LevelError,
+        Message: "possible hard-coded secret (%s) in %s",
+    },
+    WarnHighEntropySecret: {
+        Level:   LevelWarning,
+        Message: "high-entropy %d-character token (%.1f bits/char) in %s may be a secret",
+    },
+    WarnSecretInComment: {
+        Level:   LevelWarning,
+        Message: "possible hard-coded secret (%s) in comment",
+    },
+    ErrSwitchMissingDefault: {
+        Level:   LevelError,
+        Message: "switch has no 'default' label",
+    },
+    ErrSwitchDefaultPosition: {
+        Level:   LevelError,
+        Message: "'default' must be the first or the last label of the switch",
+    },
+    ErrSwitchEmpty: {
+        Level:   LevelError,
+        Message: "switch has no labels",
+    },
+    WarnSwitchSingleCase: {
+        Level:   LevelWarning,
+        Message: "switch has a single 'case' label, use 'if' instead",
+    },
+    WarnSwitchMissingEnumerators: {
+        Level:   LevelWarning,
+        Message: "switch over '%s' does not handle %s",
+    },
+    ErrMacroParamNotParenthesized: {
+        Level:   LevelError,
+        Message: "parameter '%s' must be parenthesized in the body of macro '%s'",
+    },
+    ErrMacroNotDoWhile: {
+        Level:   LevelError,
+        Message: "multi-statement macro '%s' must be wrapped in 'do { ... } while (0)'",
+    },
+    ErrMacroTrailingSemicolon: {
+        Level:   LevelError,
+        Message: "macro '%s' must not end with ';', the caller supplies it",
+    },
+    WarnMacroParamEvaluatedTwice: {
+        Level:   LevelWarning,
+        Message: "parameter '%s' of macro '%s' is evaluated %d times; arguments with side effects repeat",
+    },
+    ErrMacroUnbalancedParens: {
+        Level:   LevelError,
+        Message: "unbalanced parentheses in macro '%s' (lines %d-%d)",
+    },
+    ErrMacroRedefined: {
+        Level:   LevelError,
+        Message: "macro '%s' redefined without '#undef' (previous definition on line %d)",
+    },
+    ErrBackslashNotAligned: {
+        Level:   LevelError,
+        Message: "continuation backslash at column %d must be aligned with the others at column %d",
     },
     ErrIncludeDirectiveIndentation: {
         Level:   LevelError,
@@ -246,12 +889,13 @@ var errorInfos = [NumErrorMessages]ErrorInfo{
         Level:   LevelError,
         Message: "multiple consecutive spaces between tokens",
     },
-    ErrPointerFormattingRules: {
-        Level: LevelError,
-        Message: "pointer must be formatted as:\n" +
-            "- 'type *ptr' for declarations\n" +
-            "- '*ptr' or '*type' for dereferences\n" +
-            "- 'type *' for casting",
+    ErrPointerStarStyle: {
+        Level:   LevelError,
+        Message: "pointer '*' in %s must be written as '%s' (%s style)",
+    },
+    ErrPointerMustBeAttached: {
+        Level:   LevelError,
+        Message: "'*' of %s must be attached to its operand",
     },
     ErrPointerCastMustBeAttached: {
         Level: LevelError,
@@ -284,20 +928,12 @@ var errorInfos = [NumErrorMessages]ErrorInfo{
     },
     WarnMagicNumberDetected: {
         Level:   LevelWarning,
-        Message: "magic number '%s' detected; extract to constant",
+        Message: "magic number '%s' %s; extract it to a named constant",
     },
     ErrFuncNameNoSpaceBeforeParen: {
         Level:   LevelError,
         Message: "no space allowed between function name and '('",
     },
-    ErrFunctionNameMustBeModuleCamelCase: {
-        Level:   LevelError,
-        Message: "function name '%s' must follow MODULE_camelCase",
-    },
-    ErrParameterLineWrongIndent: {
-        Level:   LevelError,
-        Message: "parameter line should be indented to %d spaces (found %d)",
-    },
     ErrParameterLineMustEndWithComma: {
         Level:   LevelError,
         Message: "parameter line must end with ','",
@@ -306,10 +942,6 @@ var errorInfos = [NumErrorMessages]ErrorInfo{
         Level:   LevelError,
         Message: "label must have no indentation",
     },
-    ErrLabelMustBeSnakeLowerCase: {
-        Level:   LevelError,
-        Message: "label '%s' must be snake_lower_case",
-    },
     ErrColonMustBeAttachedToToken: {
         Level:   LevelError,
         Message: "':' must be attached without space to preceding token",
@@ -352,56 +984,24 @@ var errorInfos = [NumErrorMessages]ErrorInfo{
     },
     WarnCaseBlockMissingBreakOrFallthrough: {
         Level:   LevelWarning,
-        Message: "'%s' block must end with a break; or have a '// fall-through' comment",
+        Message: "'%s' block must end with a break; or be marked with a '// fall-through' comment or [[fallthrough]]",
     },
     ErrExpectedSpaceAfterClosingBrace: {
         Level:   LevelError,
         Message: "expected space after '}'",
     },
-    ErrInstanceMustBeSnakeLowerCase: {
-        Level:   LevelError,
-        Message: "%s instance '%s' must be snake_lower_case",
-    },
-    ErrInstanceMustNotEndWithT: {
-        Level:   LevelError,
-        Message: "%s instance '%s' must not end with '_t'",
-    },
-    ErrTypeTagMustBeCamelCase: {
-        Level:   LevelError,
-        Message: "%s tag '%s' must be camelCase",
-    },
     WarnDeclaredWithoutInitialization: {
         Level:   LevelWarning,
         Message: "'%s' declared without initialization",
     },
-    ErrVariableNameMustNotEndWithT: {
-        Level:   LevelError,
-        Message: "variable name '%s' must not end with '_t'",
-    },
     ErrMultipleVariableDeclarationsNotAllowed: {
         Level:   LevelError,
         Message: "multiple variable declarations not allowed; use one line per variable",
     },
-    WarnTypedefFuncPtrNameMustBeSnakeLowerCaseAndEndWithT: {
-        Level:   LevelWarning,
-        Message: "typedef name '%s' must be snake_lower_case and end with '_t'",
-    },
-    WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT: {
-        Level:   LevelWarning,
-        Message: "typedef name '%s' must be snake_lower_case and end with '_t'",
-    },
-    ErrMacroNameMustBeScreamingSnakeCase: {
-        Level:   LevelError,
-        Message: "macro name '%s' must be SCREAMING_SNAKE_CASE",
-    },
     ErrFunctionLikeMacroBodyMustBeParenthesized: {
         Level:   LevelError,
         Message: "function-like macro body must be parenthesized, e.g. ((x)*(x))",
     },
-    ErrParameterNameMustBeSnakeLowerCase: {
-        Level:   LevelError,
-        Message: "parameter name '%s' must be snake_lower_case",
-    },
     ErrTernaryQuestionMarkMustHaveSpaceBefore: {
         Level:   LevelError,
         Message: "operator '?' must have space before it",
@@ -446,80 +1046,279 @@ var errorInfos = [NumErrorMessages]ErrorInfo{
         Level:   LevelError,
         Message: "expected space after '{'",
     },
-    ErrEnumElementMustBeScreamingSnakeCase: {
+    WarnTypedefMissingName: {
+        Level:   LevelWarning,
+        Message: "typedef %s must declare a type name",
+    },
+    ErrNameCaseStyle: {
         Level:   LevelError,
-        Message: "enum element '%s' must be SCREAMING_SNAKE_CASE",
+        Message: "%s name '%s' must be %s",
     },
-    ErrStructFieldMustBeSnakeLowerCase: {
+    ErrNameMissingPrefix: {
         Level:   LevelError,
-        Message: "%s field name '%s' must be snake_lower_case",
+        Message: "%s name '%s' must start with '%s'",
     },
-}
-
-/** ===============================================================
- *              R E G E X  D E F I N I T I O N S
- * ================================================================ */
-var (
-    typeNames      = []string{"int", "char", "float", "double", "long", "short", "bool", "void"}
-    typePattern    = "(" + strings.Join(typeNames, "|") + ")"
-    typedefPattern = "[A-Za-z_][A-Za-z0-9_]*_t"
-    structPattern  = `(?:[A-Z][A-Za-z0-9]*|[a-z][A-Za-z0-9]*[A-Z][A-Za-z0-9]*)`
-    typeOrTypedef  = "(?:" + typePattern + "|" + typedefPattern + ")"
-    ptrPattern     = `\b(?:` + typePattern + `|[A-Za-z_][A-Za-z0-9_]*_t)\*`
-    typeGroup      = "(?:" +
-        "\\b" + typePattern + "\\b" + "|" +
-        "\\b" + typedefPattern + "\\b" + "|" +
-        "\\b" + structPattern + "\\b" +
-        ")"
-)
-
-var (
-    reControlStmt   = regexp.MustCompile(`^\s*(?:typedef\s+)?(if|else|for|while|switch|struct|union|enum)\b`)
-    reBraceOnlyLine = regexp.MustCompile(`^\s*\{\s*$`)
-    reTodo          = regexp.MustCompile(`\b(?:TODO|FIXME)\b`)
-    reClosingAll    = regexp.MustCompile(
-        `^\s*\}` +
-            `\s*` +
-            `([A-Za-z_][A-Za-z0-9_]*)?` +
-            `\s*;?\s*$`,
-    )
-    reSplitFuncName = regexp.MustCompile(
-        `^\s*([A-Za-z_][A-Za-z0-9_]*)\s*\(`,
-    )
-    snakePattern          = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
-    snakeTypedefPattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*_t$`)
-    screamingSnakePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
-    reMacroDefLine        = regexp.MustCompile(
-        `^\s*` +
-            `(#\s*define)` +
-            `\s+([A-Za-z_][A-Za-z0-9_]*)` +
-            `(?:\(([^\)]*)\))?`,
-    )
-    reLabelDecl       = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)(\s*):$`)
-    reTypedefFuncPtr  = regexp.MustCompile(`^\s*typedef\b.*\(\s*\*\s*([A-Za-z_][A-Za-z0-9_]*)\s*\)`)
-    reTypedefGeneric  = regexp.MustCompile(`^\s*typedef\b.*\b([A-Za-z_][A-Za-z0-9_]*)\s*;`)
-    reDefine          = regexp.MustCompile(`^\s*#\s*define\s+([A-Za-z_][A-Za-z0-9_]*)`)
-    reEnumElement     = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(?:=|,)\s*`)
-    reMultiVarDecl    = regexp.MustCompile(`^\s*(?:[A-Za-z_][A-Za-z0-9_]*\s+)+(?:\*\s*)?[A-Za-z_][A-Za-z0-9_]*\s*,`)
-    reStructFieldName = regexp.MustCompile(`^\s*[A-Za-z_][A-Za-z0-9_]*\s*(?:\*\s*)?([A-Za-z_][A-Za-z0-9_]*)\s*;`)
-    reBadBracketSpace = regexp.MustCompile(`\[\s+|[ \t]+\]`)
-    reVarDeclName     = regexp.MustCompile(
-        `^\s*(?:[A-Za-z_][A-Za-z0-9_]*\s+)+(?:\*\s*)?` +
-            `([A-Za-z_][A-Za-z0-9_]*)\s*(?:=|;).*`,
-    )
-    reMacroDef = regexp.MustCompile(
-        `^\s*#\s*define\s+` +
-            `([A-Za-z_][A-Za-z0-9_]*)\s*` +
-            `\(\s*([^)]*)\)\s*` +
-            `(.*)$`,
-    )
-    reIdent          = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
-    reKeywordNoSpace = regexp.MustCompile(
-        `\b(if|else|for|while|return|break|continue|switch|case|default|static|` +
+    ErrNameMissingSuffix: {
+        Level:   LevelError,
+        Message: "%s name '%s' must end with '%s'",
+    },
+    ErrNameForbiddenSuffix: {
+        Level:   LevelError,
+        Message: "%s name '%s' must not end with '%s'",
+    },
+    ErrNameTooShort: {
+        Level:   LevelError,
+        Message: "%s name '%s' must have at least %d characters",
+    },
+    ErrNameTooLong: {
+        Level:   LevelError,
+        Message: "%s name '%s' must have at most %d characters",
+    },
+    ErrNameMissingModulePrefix: {
+        Level:   LevelError,
+        Message: "%s name '%s' must use the module prefix '%s'",
+    },
+    ErrFunctionNotDeclaredInHeader: {
+        Level:   LevelError,
+        Message: "non-static function '%s' is not declared in '%s'",
+    },
+    ErrPrototypeWithoutDefinition: {
+        Level:   LevelError,
+        Message: "prototype '%s' has no definition in '%s'",
+    },
+    ErrPrototypeReturnTypeMismatch: {
+        Level:   LevelError,
+        Message: "return type of '%s' is '%s' but '%s' in '%s'",
+    },
+    ErrPrototypeParamCountMismatch: {
+        Level:   LevelError,
+        Message: "'%s' takes %d parameter(s) but is declared with %d in '%s'",
+    },
+    ErrPrototypeParamNameMismatch: {
+        Level:   LevelError,
+        Message: "parameter %d of '%s' is named '%s' but '%s' in '%s'",
+    },
+    ErrPrototypeParamTypeMismatch: {
+        Level:   LevelError,
+        Message: "parameter %d of '%s' has type '%s' but '%s' in '%s'",
+    },
+    ErrOwnHeaderMustBeIncludedFirst: {
+        Level:   LevelError,
+        Message: "own header '%s' must be the first include",
+    },
+    ErrHeaderVariableDefinition: {
+        Level:   LevelError,
+        Message: "header must not define variable '%s'; declare it 'extern' and define it in a source file",
+    },
+    ErrHeaderFunctionDefinition: {
+        Level:   LevelError,
+        Message: "header must not define function '%s'; only 'static inline' functions may have a body",
+    },
+    ErrHeaderStaticDeclaration: {
+        Level:   LevelError,
+        Message: "header must not declare 'static' '%s' unless it is a 'static inline' function",
+    },
+    ErrHeaderMissingExternC: {
+        Level:   LevelError,
+        Message: "public header must wrap its declarations in '#ifdef __cplusplus extern \"C\" {'",
+    },
+    ErrHeaderExternCNotGuarded: {
+        Level:   LevelError,
+        Message: "'extern \"C\"' must be guarded by '#ifdef __cplusplus'",
+    },
+    ErrHeaderDeclOutsideExternC: {
+        Level:   LevelError,
+        Message: "declaration of '%s' is outside the 'extern \"C\"' block",
+    },
+    ErrDoxygenMissing: {
+        Level:   LevelError,
+        Message: "function '%s' must be documented by a '/** ... */' block right above it",
+    },
+    ErrDoxygenParamMismatch: {
+        Level:   LevelError,
+        Message: "@param tags of '%s' (%s) do not match its parameters (%s)",
+    },
+    ErrDoxygenMissingReturn: {
+        Level:   LevelError,
+        Message: "documentation of '%s' must have @return for return type '%s'",
+    },
+    ErrDoxygenUnexpectedReturn: {
+        Level:   LevelError,
+        Message: "documentation of '%s' must not have @return for a void function",
+    },
+    WarnDoxygenUnknownTag: {
+        Level:   LevelWarning,
+        Message: "unknown Doxygen tag '%s'",
+    },
+    ErrBannerMissing: {
+        Level:   LevelError,
+        Message: "file must start with a banner comment holding the copyright and SPDX license",
+    },
+    ErrBannerMissingCopyright: {
+        Level:   LevelError,
+        Message: "file banner must contain a copyright line",
+    },
+    ErrBannerInvalidYear: {
+        Level:   LevelError,
+        Message: "invalid copyright year range '%s'",
+    },
+    ErrBannerMissingSPDX: {
+        Level:   LevelError,
+        Message: "file banner must contain an 'SPDX-License-Identifier' line",
+    },
+    ErrBannerLicenseNotAllowed: {
+        Level:   LevelError,
+        Message: "license '%s' is not allowed (use one of: %s)",
+    },
+    ErrBannerTemplateMismatch: {
+        Level:   LevelError,
+        Message: "file banner does not match the template line '%s'",
+    },
+    WarnFunctionTooComplex: {
+        Level:   LevelWarning,
+        Message: "function '%s' has cyclomatic complexity %d (max %d)",
+    },
+    WarnFunctionTooDeep: {
+        Level:   LevelWarning,
+        Message: "function '%s' has nesting depth %d (max %d)",
+    },
+    WarnFunctionTooManyStatements: {
+        Level:   LevelWarning,
+        Message: "function '%s' has %d statements (max %d)",
+    },
+    WarnFunctionTooLong: {
+        Level:   LevelWarning,
+        Message: "function '%s' spans %d lines (max %d)",
+    },
+    WarnFunctionTooManyParams: {
+        Level:   LevelWarning,
+        Message: "function '%s' has %d parameters (max %d)",
+    },
+    WarnFunctionTooManyReturns: {
+        Level:   LevelWarning,
+        Message: "function '%s' has %d return points (max %d)",
+    },
+    ErrIndentTabsNotAllowed: {
+        Level:   LevelError,
+        Message: "indentation must use spaces, not tabs",
+    },
+    ErrIndentSpacesNotAllowed: {
+        Level:   LevelError,
+        Message: "indentation must use tabs, not spaces",
+    },
+    ErrMixedIndentation: {
+        Level:   LevelError,
+        Message: "mixed indentation: tabs and spaces in the same indent",
+    },
+    ErrBlockBodyIndent: {
+        Level:   LevelError,
+        Message: "line must be indented by %d columns inside %s (found %d)",
+    },
+    ErrCaseLabelIndent: {
+        Level:   LevelError,
+        Message: "case label must be indented by %d columns inside %s (found %d)",
+    },
+    ErrContinuationIndent: {
+        Level:   LevelError,
+        Message: "continuation line must be indented by %d columns to follow %s (found %d)",
+    },
+    ErrDirectiveIndent: {
+        Level:   LevelError,
+        Message: "preprocessor directive '%s' must not be indented (found %d columns)",
+    },
+    ErrClosingBraceIndent: {
+        Level:   LevelError,
+        Message: "closing brace must be indented by %d columns to close %s (found %d)",
+    },
+    ErrOperatorAtLineStart: {
+        Level:   LevelError,
+        Message: "operator '%s' must end the broken line instead of starting the next one",
+    },
+    ErrOperatorAtLineEnd: {
+        Level:   LevelError,
+        Message: "operator '%s' must start the continuation line instead of ending the broken one",
+    },
+    ErrOneArgumentPerLine: {
+        Level:   LevelError,
+        Message: "wrapped call to '%s' must have one argument per line",
+    },
+    ErrCommentLineLengthExceeded: {
+        Level:   LevelError,
+        Message: "comment line length must not exceed %d characters, found %d",
+    },
+    WarnLineLengthSoftLimit: {
+        Level:   LevelWarning,
+        Message: "line length exceeds the soft limit of %d characters, found %d",
+    },
+}
+
+/** ===============================================================
+ *              R E G E X  D E F I N I T I O N S
+ * ================================================================ */
+var (
+    typeNames      = []string{"int", "char", "float", "double", "long", "short", "bool", "void"}
+    typePattern    = "(" + strings.Join(typeNames, "|") + ")"
+    typedefPattern = "[A-Za-z_][A-Za-z0-9_]*_t"
+    structPattern  = `(?:[A-Z][A-Za-z0-9]*|[a-z][A-Za-z0-9]*[A-Z][A-Za-z0-9]*)`
+    typeOrTypedef  = "(?:" + typePattern + "|" + typedefPattern + ")"
+    typeGroup      = "(?:" +
+        "\\b" + typePattern + "\\b" + "|" +
+        "\\b" + typedefPattern + "\\b" + "|" +
+        "\\b" + structPattern + "\\b" +
+        ")"
+)
+
+var (
+    reControlStmt    = regexp.MustCompile(`^\s*(?:typedef\s+)?(if|else|for|while|switch|struct|union|enum)\b`)
+    reCuddledElse    = regexp.MustCompile(`^}\s*else\b`)
+    reDoWhileBody    = regexp.MustCompile(`^do\b`)
+    reLogicalComment = regexp.MustCompile(`/\*.*?\*/|//.*$`)
+    reOctalConst     = regexp.MustCompile(`^0[0-7]+[uUlL]*$`)
+    reBraceOnlyLine  = regexp.MustCompile(`^\s*\{\s*$`)
+    reTodo           = regexp.MustCompile(`\b(?:TODO|FIXME)\b`)
+    reClosingAll     = regexp.MustCompile(
+        `^\s*\}` +
+            `\s*` +
+            `([A-Za-z_][A-Za-z0-9_]*)?` +
+            `\s*;?\s*$`,
+    )
+    reSplitFuncName = regexp.MustCompile(
+        `^\s*([A-Za-z_][A-Za-z0-9_]*)\s*\(`,
+    )
+    snakePattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+    reMacroDefLine = regexp.MustCompile(
+        `^\s*` +
+            `(#\s*define)` +
+            `\s+([A-Za-z_][A-Za-z0-9_]*)` +
+            `(?:\(([^\)]*)\))?`,
+    )
+    reLabelDecl       = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)(\s*):$`)
+    reTypedefFuncPtr  = regexp.MustCompile(`^\s*typedef\b.*\(\s*\*\s*([A-Za-z_][A-Za-z0-9_]*)\s*\)`)
+    reMultiVarDecl    = regexp.MustCompile(`^\s*(?:[A-Za-z_][A-Za-z0-9_]*\s+)+(?:\*\s*)?[A-Za-z_][A-Za-z0-9_]*\s*,`)
+    reBadBracketSpace = regexp.MustCompile(`\[\s+|[ \t]+\]`)
+    reMacroDef        = regexp.MustCompile(
+        `^\s*#\s*define\s+` +
+            `([A-Za-z_][A-Za-z0-9_]*)\s*` +
+            `\(\s*([^)]*)\)\s*` +
+            `(.*)$`,
+    )
+    reIdent          = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+    reNonIdentChar   = regexp.MustCompile(`[^A-Za-z0-9_]`)
+    reDirectiveName  = regexp.MustCompile(`^#\s*[A-Za-z_]+`)
+    reURL            = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://\S+`)
+    reIncludeLine    = regexp.MustCompile(`^#\s*include\b`)
+    reStringOnlyLine = regexp.MustCompile(`^(?:L|u8|u|U)?"(?:[^"\\]|\\.)*"\s*[,;)]*\s*$`)
+    reCopyright      = regexp.MustCompile(`(?i)copyright\s*(?:\(c\)|©)?\s*((\d{4})(?:\s*-\s*(\d{4}))?)\b,?(.*)`)
+    reSPDX           = regexp.MustCompile(`SPDX-License-Identifier:\s*(.+)`)
+    rePlaceholder    = regexp.MustCompile(`\{([A-Za-z_]+)\}`)
+    reDocTag         = regexp.MustCompile(
+        `(?:^|[\s*])[@\\]([A-Za-z]+)(?:\[[A-Za-z, ]*\])?(?:[ \t]+([A-Za-z_][A-Za-z0-9_]*))?`,
+    )
+    reKeywordNoSpace = regexp.MustCompile(
+        `\b(if|else|for|while|return|break|continue|switch|case|default|static|` +
             `const|extern|unsigned|signed|typedef|struct|union|enum|void|sizeof)\(`,
     )
-    reMagicNumber = regexp.MustCompile(`\b([2-9][0-9]*)\b`)
-    reFuncMacro   = regexp.MustCompile(
+    reFuncMacro = regexp.MustCompile(
         `^\s*#\s*define\s+([A-Za-z_][A-Za-z0-9_]*)\s*\([^)]*\)\s+(.+)$`,
     )
     reFuncHeader = regexp.MustCompile(
@@ -540,10 +1339,6 @@ var (
         `^\s*(?:[A-Za-z_][A-Za-z0-9_]*\s+)+` +
             `([A-Za-z_][A-Za-z0-9_]*)\s*\([^)]*\)\s*(?:;|$)`,
     )
-    reFuncSignature = regexp.MustCompile(
-        `^\s*(?:[A-Za-z_][A-Za-z0-9_]*\s+)+[A-Za-z_][A-Za-z0-9_]*\s*\(([^)]*)\)\s*$`,
-    )
-    reParamName                 = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)$`)
     reTernaryQNoSpaceBefore     = regexp.MustCompile(`\S\?`)
     reTernaryQNoSpaceAfter      = regexp.MustCompile(`\?\S`)
     reTernaryColonNoSpaceBefore = regexp.MustCompile(`\S:`)
@@ -557,12 +1352,9 @@ var (
     reGenericPtrParen = regexp.MustCompile(
         `\b(?:void|int|char|float|double|long|short|bool|[A-Za-z_][A-Za-z0-9_]*_t)\s*\*\s*\)`,
     )
-    reBadDeref        = regexp.MustCompile(`\*\s+[A-Za-z_][A-Za-z0-9_]*`)
-    reBadCastLeading  = regexp.MustCompile(`\(\s*(?:void|int|char|float|double|long|short|bool)\*\)`)
-    reBadParenSpace   = regexp.MustCompile(`\(\s+|[ \t]+\)`)
-    reBadComma        = regexp.MustCompile(`\s+,|,\S|, {2,}`)
-    reTypeStarNoSpace = regexp.MustCompile(ptrPattern)
-    reBadPtrCast      = regexp.MustCompile(
+    reBadParenSpace = regexp.MustCompile(`\(\s+|[ \t]+\)`)
+    reBadComma      = regexp.MustCompile(`\s+,|,\S|, {2,}`)
+    reBadPtrCast    = regexp.MustCompile(
         `\(\s*` + typeOrTypedef + `\s*\*\s*\)\s+[A-Za-z_\(]`,
     )
     reMacroNoSpace = regexp.MustCompile(`^\s*#\s*define\s+[A-Za-z_][A-Za-z0-9_]*\([^)]*\)\S`)
@@ -618,22 +1410,31 @@ var (
             `|[=+\-*/%<>?:]`,
     )
     reIncludeStyle = regexp.MustCompile(`^\s*(#\s*include)\s+([<"].+[>"])`)
-    reCamel        = regexp.MustCompile(`^[a-z][A-Za-z0-9]*$`)
-    reFunctionName = regexp.MustCompile(`^[A-Z]+_[a-z][A-Za-z0-9]*$`)
     reBecaketCase  = regexp.MustCompile(`^(\s*)(case\s+[^:]+)\s*\{\s*$`)
     reFuncSigEnd   = regexp.MustCompile(`\)`)
-    reCombinedPtr  = regexp.MustCompile(
-        fmt.Sprintf("(%s)|(%s)|(%s)",
-            reBadDeref.String(),
-            reTypeStarNoSpace.String(),
-            reBadCastLeading.String(),
-        ),
-    )
 )
 
 /** ===============================================================
  *              G L O B A L  V A R I A B L E S
  * ================================================================ */
+var styleModes = map[string]StyleMode{
+    "kr":          StyleKR,
+    "allman":      StyleAllman,
+    "stroustrup":  StyleStroustrup,
+    "linux":       StyleLinux,
+    "gnu":         StyleGNU,
+    "whitesmiths": StyleWhitesmiths,
+}
+
+var styleNames = map[StyleMode]string{
+    StyleKR:          "K&R",
+    StyleAllman:      "Allman",
+    StyleStroustrup:  "Stroustrup",
+    StyleLinux:       "Linux kernel",
+    StyleGNU:         "GNU",
+    StyleWhitesmiths: "Whitesmiths",
+}
+
 var unsafeFuncSuggestions = map[string]string{
     "gets":     "fgets(buffer, size, stdin)",
     "strcpy":   "strlcpy(dest, src, dest_size) // or strncpy(dest, src, n)",
@@ -647,51 +1448,290 @@ var unsafeFuncSuggestions = map[string]string{
     "getwd":    "getcwd(buffer, size)",
 }
 
-var keywords = map[string]bool{
-    "auto":           true,
-    "break":          true,
-    "case":           true,
-    "char":           true,
-    "const":          true,
-    "continue":       true,
-    "default":        true,
-    "do":             true,
-    "double":         true,
-    "else":           true,
-    "enum":           true,
-    "extern":         true,
-    "float":          true,
-    "for":            true,
-    "goto":           true,
-    "if":             true,
-    "inline":         true,
-    "int":            true,
-    "long":           true,
-    "register":       true,
-    "restrict":       true,
-    "return":         true,
-    "short":          true,
-    "signed":         true,
-    "sizeof":         true,
-    "static":         true,
-    "struct":         true,
-    "switch":         true,
-    "typedef":        true,
-    "union":          true,
-    "unsigned":       true,
-    "void":           true,
-    "volatile":       true,
-    "while":          true,
-    "_Alignas":       true,
-    "_Alignof":       true,
-    "_Atomic":        true,
-    "_Bool":          true,
-    "_Complex":       true,
-    "_Generic":       true,
-    "_Imaginary":     true,
-    "_Noreturn":      true,
-    "_Static_assert": true,
-    "_Thread_local":  true,
+var stdKeywords = map[string]LangStd{
+    "auto":           StdC89,
+    "break":          StdC89,
+    "case":           StdC89,
+    "char":           StdC89,
+    "const":          StdC89,
+    "continue":       StdC89,
+    "default":        StdC89,
+    "do":             StdC89,
+    "double":         StdC89,
+    "else":           StdC89,
+    "enum":           StdC89,
+    "extern":         StdC89,
+    "float":          StdC89,
+    "for":            StdC89,
+    "goto":           StdC89,
+    "if":             StdC89,
+    "inline":         StdC99,
+    "int":            StdC89,
+    "long":           StdC89,
+    "register":       StdC89,
+    "restrict":       StdC99,
+    "return":         StdC89,
+    "short":          StdC89,
+    "signed":         StdC89,
+    "sizeof":         StdC89,
+    "static":         StdC89,
+    "struct":         StdC89,
+    "switch":         StdC89,
+    "typedef":        StdC89,
+    "union":          StdC89,
+    "unsigned":       StdC89,
+    "void":           StdC89,
+    "volatile":       StdC89,
+    "while":          StdC89,
+    "_Alignas":       StdC11,
+    "_Alignof":       StdC11,
+    "_Atomic":        StdC11,
+    "_Bool":          StdC99,
+    "_Complex":       StdC99,
+    "_Generic":       StdC11,
+    "_Imaginary":     StdC99,
+    "_Noreturn":      StdC11,
+    "_Static_assert": StdC11,
+    "_Thread_local":  StdC11,
+    "alignas":        StdC23,
+    "alignof":        StdC23,
+    "bool":           StdC23,
+    "constexpr":      StdC23,
+    "false":          StdC23,
+    "nullptr":        StdC23,
+    "static_assert":  StdC23,
+    "thread_local":   StdC23,
+    "true":           StdC23,
+    "typeof":         StdC23,
+    "typeof_unqual":  StdC23,
+    "_BitInt":        StdC23,
+    "_Decimal32":     StdC23,
+    "_Decimal64":     StdC23,
+    "_Decimal128":    StdC23,
+}
+
+var keywords = languageKeywords(StdC17)
+
+var stdHeaderWords = map[string]LangStd{
+    "bool":          StdC99,
+    "true":          StdC99,
+    "false":         StdC99,
+    "static_assert": StdC11,
+    "alignas":       StdC11,
+    "alignof":       StdC11,
+    "thread_local":  StdC11,
+}
+
+var obsolescentSpellings = map[string]string{
+    "_Bool":          "bool",
+    "_Static_assert": "static_assert",
+    "_Alignas":       "alignas",
+    "_Alignof":       "alignof",
+    "_Thread_local":  "thread_local",
+    "_Noreturn":      "[[noreturn]]",
+}
+
+var dynamicAllocFuncs = map[string]bool{
+    "malloc":        true,
+    "calloc":        true,
+    "realloc":       true,
+    "aligned_alloc": true,
+    "free":          true,
+    "strdup":        true,
+    "strndup":       true,
+}
+
+var statusReturningFuncs = map[string]bool{
+    "fclose":   true,
+    "fflush":   true,
+    "fputs":    true,
+    "fputc":    true,
+    "fwrite":   true,
+    "fread":    true,
+    "fseek":    true,
+    "fgets":    true,
+    "remove":   true,
+    "rename":   true,
+    "snprintf": true,
+    "sscanf":   true,
+    "fscanf":   true,
+    "setvbuf":  true,
+    "close":    true,
+    "read":     true,
+    "write":    true,
+}
+
+var bidiControls = map[rune]string{
+    0x061C: "ARABIC LETTER MARK",
+    0x200E: "LEFT-TO-RIGHT MARK",
+    0x200F: "RIGHT-TO-LEFT MARK",
+    0x202A: "LEFT-TO-RIGHT EMBEDDING",
+    0x202B: "RIGHT-TO-LEFT EMBEDDING",
+    0x202C: "POP DIRECTIONAL FORMATTING",
+    0x202D: "LEFT-TO-RIGHT OVERRIDE",
+    0x202E: "RIGHT-TO-LEFT OVERRIDE",
+    0x2066: "LEFT-TO-RIGHT ISOLATE",
+    0x2067: "RIGHT-TO-LEFT ISOLATE",
+    0x2068: "FIRST STRONG ISOLATE",
+    0x2069: "POP DIRECTIONAL ISOLATE",
+}
+
+var invisibleChars = map[rune]string{
+    0x00AD: "SOFT HYPHEN",
+    0x180E: "MONGOLIAN VOWEL SEPARATOR",
+    0x200B: "ZERO WIDTH SPACE",
+    0x200C: "ZERO WIDTH NON-JOINER",
+    0x200D: "ZERO WIDTH JOINER",
+    0x2060: "WORD JOINER",
+    0x2061: "FUNCTION APPLICATION",
+    0x2062: "INVISIBLE TIMES",
+    0x2063: "INVISIBLE SEPARATOR",
+    0x2064: "INVISIBLE PLUS",
+    0xFEFF: "ZERO WIDTH NO-BREAK SPACE",
+}
+
+var homoglyphs = map[rune]rune{
+    0x0430: 'a', 0x0435: 'e', 0x043E: 'o', 0x0440: 'p', 0x0441: 'c',
+    0x0443: 'y', 0x0445: 'x', 0x0455: 's', 0x0456: 'i', 0x0458: 'j',
+    0x0501: 'd', 0x04BB: 'h', 0x0405: 'S', 0x0406: 'I', 0x0408: 'J',
+    0x0410: 'A', 0x0412: 'B', 0x0415: 'E', 0x041A: 'K', 0x041C: 'M',
+    0x041D: 'H', 0x041E: 'O', 0x0420: 'P', 0x0421: 'C', 0x0422: 'T',
+    0x0425: 'X', 0x0391: 'A', 0x0392: 'B', 0x0395: 'E', 0x0396: 'Z',
+    0x0397: 'H', 0x0399: 'I', 0x039A: 'K', 0x039C: 'M', 0x039D: 'N',
+    0x039F: 'O', 0x03A1: 'P', 0x03A4: 'T', 0x03A5: 'Y', 0x03A7: 'X',
+    0x03B1: 'a', 0x03BD: 'v', 0x03BF: 'o', 0x03C1: 'p',
+}
+
+var builtinSecretPatterns = []secretPattern{
+    {"PEM block", regexp.MustCompile(`-----BEGIN [A-Z0-9 ]+-----`)},
+    {"AWS access key ID", regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`)},
+    {"JWT", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}`)},
+    {"256-bit hex key", regexp.MustCompile(`\b[0-9a-fA-F]{64}\b`)},
+    {"128-bit hex key", regexp.MustCompile(`\b[0-9a-fA-F]{32}\b`)},
+    {"credential assignment", regexp.MustCompile(
+        `(?i)\b(?:password|passwd|pwd|psk|passphrase|secret|api_?key|access_?token|auth_?token)\s*[:=]\s*` +
+            `(?:"[^"\s%]{6,}"|'[^'\s%]{6,}'|[^\s"'%]*(?:[a-z][^\s"'%]*[0-9]|[0-9][^\s"'%]*[a-z])[^\s"'%]*)`)},
+}
+
+var conversionBaseFuncs = map[string]bool{
+    "strtol":    true,
+    "strtoul":   true,
+    "strtoll":   true,
+    "strtoull":  true,
+    "strtoimax": true,
+    "strtoumax": true,
+    "wcstol":    true,
+    "wcstoul":   true,
+}
+
+var certRules = map[string]bool{
+    "FIO30-C": true,
+    "MEM31-C": true,
+    "ERR33-C": true,
+    "ARR01-C": true,
+    "ERR34-C": true,
+    "SIG30-C": true,
+}
+
+var certFormatArg = map[string]int{
+    "printf":    0,
+    "vprintf":   0,
+    "fprintf":   1,
+    "vfprintf":  1,
+    "dprintf":   1,
+    "vdprintf":  1,
+    "sprintf":   1,
+    "vsprintf":  1,
+    "asprintf":  1,
+    "vasprintf": 1,
+    "syslog":    1,
+    "vsyslog":   1,
+    "snprintf":  2,
+    "vsnprintf": 2,
+}
+
+var certTranslationFuncs = map[string]bool{
+    "_":        true,
+    "N_":       true,
+    "gettext":  true,
+    "dgettext": true,
+}
+
+var certSizeArg = map[string]int{
+    "memcpy":   2,
+    "memmove":  2,
+    "memset":   2,
+    "memcmp":   2,
+    "strncpy":  2,
+    "strncat":  2,
+    "strncmp":  2,
+    "snprintf": 1,
+    "fgets":    1,
+}
+
+var certConversionFuncs = map[string]string{
+    "atoi":  "strtol",
+    "atol":  "strtol",
+    "atoll": "strtoll",
+    "atof":  "strtod",
+}
+
+var asyncSignalSafeFuncs = map[string]bool{
+    "abort":                    true,
+    "_Exit":                    true,
+    "quick_exit":               true,
+    "signal":                   true,
+    "_exit":                    true,
+    "write":                    true,
+    "read":                     true,
+    "open":                     true,
+    "close":                    true,
+    "kill":                     true,
+    "raise":                    true,
+    "getpid":                   true,
+    "alarm":                    true,
+    "pause":                    true,
+    "wait":                     true,
+    "waitpid":                  true,
+    "sigaction":                true,
+    "sigprocmask":              true,
+    "sigemptyset":              true,
+    "sigfillset":               true,
+    "sigaddset":                true,
+    "sigdelset":                true,
+    "sigismember":              true,
+    "sem_post":                 true,
+    "atomic_store":             true,
+    "atomic_load":              true,
+    "atomic_exchange":          true,
+    "atomic_fetch_add":         true,
+    "atomic_fetch_sub":         true,
+    "atomic_flag_test_and_set": true,
+    "atomic_flag_clear":        true,
+    "atomic_signal_fence":      true,
+}
+
+var misraRules = map[string]bool{
+    "Dir 4.9": true,
+    "5.1":     true,
+    "5.2":     true,
+    "5.4":     true,
+    "7.1":     true,
+    "7.2":     true,
+    "15.2":    true,
+    "15.5":    true,
+    "15.6":    true,
+    "16.5":    true,
+}
+
+var langStds = map[string]LangStd{
+    "c89": StdC89,
+    "c90": StdC89,
+    "c99": StdC99,
+    "c11": StdC11,
+    "c17": StdC17,
+    "c18": StdC17,
+    "c23": StdC23,
 }
 
 var typesMap = map[string]bool{
@@ -704,6 +1744,149 @@ var typesMap = map[string]bool{
     "bool":   true,
 }
 
+var entityKindNames = map[EntityKind]string{
+    KindFunction:       "function",
+    KindStaticFunction: "static function",
+    KindGlobal:         "global variable",
+    KindStaticGlobal:   "static global variable",
+    KindLocal:          "local variable",
+    KindParameter:      "parameter",
+    KindMacro:          "macro",
+    KindFunctionMacro:  "function-like macro",
+    KindTypedef:        "typedef",
+    KindStructTag:      "struct tag",
+    KindEnumTag:        "enum tag",
+    KindEnumerator:     "enumerator",
+    KindField:          "field",
+    KindLabel:          "label",
+}
+
+var namingConfigKeys = map[EntityKind]string{
+    KindFunction:       "function",
+    KindStaticFunction: "static_function",
+    KindGlobal:         "global",
+    KindStaticGlobal:   "static_global",
+    KindLocal:          "local",
+    KindParameter:      "parameter",
+    KindMacro:          "macro",
+    KindFunctionMacro:  "function_macro",
+    KindTypedef:        "typedef",
+    KindStructTag:      "struct_tag",
+    KindEnumTag:        "enum_tag",
+    KindEnumerator:     "enumerator",
+    KindField:          "field",
+    KindLabel:          "label",
+}
+
+var caseStylePatterns = map[string]*regexp.Regexp{
+    "snake_case":           regexp.MustCompile(`^[a-z][a-z0-9_]*$`),
+    "SCREAMING_SNAKE_CASE": regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`),
+    "camelCase":            regexp.MustCompile(`^[a-z][A-Za-z0-9]*$`),
+    "PascalCase":           regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`),
+    "MODULE_camelCase":     regexp.MustCompile(`^[A-Z][A-Z0-9]*_[a-z][A-Za-z0-9]*$`),
+}
+
+var declSpecWords = map[string]bool{
+    "typedef":       true,
+    "extern":        true,
+    "static":        true,
+    "auto":          true,
+    "register":      true,
+    "const":         true,
+    "volatile":      true,
+    "restrict":      true,
+    "inline":        true,
+    "__inline__":    true,
+    "_Noreturn":     true,
+    "_Thread_local": true,
+    "_Atomic":       true,
+    "constexpr":     true,
+}
+
+var baseTypeWords = map[string]bool{
+    "void":     true,
+    "char":     true,
+    "short":    true,
+    "int":      true,
+    "long":     true,
+    "float":    true,
+    "double":   true,
+    "signed":   true,
+    "unsigned": true,
+    "_Bool":    true,
+    "bool":     true,
+    "_Complex": true,
+}
+
+var doxygenTags = map[string]bool{
+    "a": true, "addindex": true, "addtogroup": true, "anchor": true, "arg": true,
+    "attention": true, "author": true, "authors": true, "b": true, "brief": true,
+    "bug": true, "c": true, "callergraph": true, "callgraph": true, "category": true,
+    "cite": true, "class": true, "code": true, "collaborationgraph": true,
+    "concept": true, "cond": true, "copybrief": true, "copydetails": true,
+    "copydoc": true, "copyright": true, "date": true, "def": true, "defgroup": true,
+    "deprecated": true, "details": true, "diafile": true, "dir": true,
+    "directorygraph": true, "docbookinclude": true, "docbookonly": true,
+    "dontinclude": true, "dot": true, "dotfile": true, "doxyconfig": true, "e": true,
+    "else": true, "elseif": true, "em": true, "emoji": true, "endcode": true,
+    "endcond": true, "enddocbookonly": true, "enddot": true, "endhtmlonly": true,
+    "endif": true, "endinternal": true, "endlatexonly": true, "endlink": true,
+    "endmanonly": true, "endmsc": true, "endparblock": true, "endrtfonly": true,
+    "endsecreflist": true, "enduml": true, "endverbatim": true, "endxmlonly": true,
+    "enum": true, "example": true, "exception": true, "extends": true, "f": true,
+    "file": true, "fileinfo": true, "fn": true, "groupgraph": true, "headerfile": true,
+    "hidecallergraph": true, "hidecallgraph": true, "hidecollaborationgraph": true,
+    "hidedirectorygraph": true, "hideenumvalues": true, "hidegroupgraph": true,
+    "hideincludedbygraph": true, "hideincludegraph": true, "hideinheritancegraph": true,
+    "hideinitializer": true, "hideinlinesource": true, "hiderefby": true,
+    "hiderefs": true, "htmlinclude": true, "htmlonly": true, "idlexcept": true,
+    "if": true, "ifnot": true, "image": true, "implements": true, "important": true,
+    "include": true, "includedbygraph": true, "includedoc": true, "includegraph": true,
+    "includelineno": true, "ingroup": true, "inheritancegraph": true, "interface": true,
+    "internal": true, "invariant": true, "latexinclude": true, "latexonly": true,
+    "li": true, "line": true, "lineinfo": true, "link": true, "mainpage": true,
+    "maninclude": true, "manonly": true, "memberof": true, "module": true, "msc": true,
+    "mscfile": true, "n": true, "name": true, "namespace": true, "noop": true,
+    "nosubgrouping": true, "note": true, "overload": true, "p": true, "package": true,
+    "page": true, "par": true, "paragraph": true, "param": true, "parblock": true,
+    "plantumlfile": true, "post": true, "pre": true, "private": true,
+    "privatesection": true, "property": true, "protected": true,
+    "protectedsection": true, "protocol": true, "public": true, "publicsection": true,
+    "pure": true, "qualifier": true, "raisewarning": true, "ref": true, "refitem": true,
+    "related": true, "relatedalso": true, "relates": true, "relatesalso": true,
+    "remark": true, "remarks": true, "result": true, "return": true, "returns": true,
+    "retval": true, "rtfinclude": true, "rtfonly": true, "sa": true, "secreflist": true,
+    "section": true, "see": true, "short": true, "showdate": true,
+    "showenumvalues": true, "showinitializer": true, "showinlinesource": true,
+    "showrefby": true, "showrefs": true, "since": true, "skip": true, "skipline": true,
+    "snippet": true, "snippetdoc": true, "snippetlineno": true, "startuml": true,
+    "static": true, "struct": true, "subpage": true, "subsection": true,
+    "subsubsection": true, "tableofcontents": true, "test": true, "throw": true,
+    "throws": true, "todo": true, "tparam": true, "typedef": true, "union": true,
+    "until": true, "var": true, "verbatim": true, "verbinclude": true, "version": true,
+    "vhdlflow": true, "warning": true, "weakgroup": true, "xmlinclude": true,
+    "xmlonly": true, "xrefitem": true,
+}
+
+var wellKnownTypes = map[string]bool{
+    "FILE":    true,
+    "va_list": true,
+    "jmp_buf": true,
+}
+
+var binaryOperators = map[string]bool{
+    "&&": true, "||": true, "==": true, "!=": true, "<": true, ">": true,
+    "<=": true, ">=": true, "+": true, "-": true, "*": true, "/": true,
+    "%": true, "&": true, "|": true, "^": true, "<<": true, ">>": true,
+    "?": true, ":": true,
+}
+
+var punctuators = []string{
+    "...", "<<=", ">>=",
+    "->", "++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||",
+    "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "##",
+}
+
 var operatorRunes = map[rune]bool{
     '+': true,
     '-': true,
@@ -775,41 +1958,139 @@ const (
  *                  M A I N  F U N C T I O N
  * ================================================================ */
 func main() {
-    styleFlag := flag.String("style", "kr", "style mode (\"kr\" or \"allman\")")
+    styleFlag := flag.String("style", "kr", "brace style (\"kr\", \"allman\", \"stroustrup\", \"linux\", \"gnu\", \"whitesmiths\" or \"auto\")")
+    configFlag := flag.String("config", "", "path to a JSON configuration file")
+    fixFlag := flag.Bool("fix", false, "insert or update the file banner before checking")
+    metricsFlag := flag.String("metrics", "", "print per-function metrics of the given files (\"json\" or \"csv\")")
+    reportFlag := flag.String("report", "", "print an aggregated report instead of each finding (\"summary\")")
+    inferFlag := flag.Bool("infer-config", false, "print a configuration inferred from the given files")
+    stdFlag := flag.String("std", "", "C language standard (\"c89\", \"c99\", \"c11\", \"c17\" or \"c23\"; default from the config, c17)")
     flag.Parse()
 
-    if flag.NArg() != 1 {
-        fmt.Fprintf(os.Stderr, "Usage: %s [--style=kr|allman] <file.c/h>\n", os.Args[0])
+    if *inferFlag && flag.NArg() > 0 {
+        if err := inferConfig(flag.Args()); err != nil {
+            fmt.Fprintf(os.Stderr, "Failed to infer configuration: %v\n", err)
+            os.Exit(1)
+        }
+        os.Exit(0)
+    }
+
+    if *metricsFlag != "" && flag.NArg() > 0 {
+        if err := metricsReport(*metricsFlag, flag.Args()); err != nil {
+            fmt.Fprintf(os.Stderr, "Failed to compute metrics: %v\n", err)
+            os.Exit(1)
+        }
+        os.Exit(0)
+    }
+
+    if flag.NArg() == 0 {
+        fmt.Fprintf(os.Stderr, "Usage: %s [--style=kr|allman|stroustrup|linux|gnu|whitesmiths|auto] [--std=c89|c99|c11|c17|c23] [--config=file.json] [--fix] [--report=summary] <file.c/h>...\n", os.Args[0])
+        fmt.Fprintf(os.Stderr, "       %s --metrics=json|csv <file.c/h>...\n", os.Args[0])
+        fmt.Fprintf(os.Stderr, "       %s --infer-config <file.c/h>...\n", os.Args[0])
         os.Exit(1)
     }
-    filename := flag.Arg(0)
+    if *reportFlag != "" && *reportFlag != "summary" {
+        fmt.Fprintf(os.Stderr, "Error: unknown report %q (use \"summary\")\n", *reportFlag)
+        os.Exit(1)
+    }
+
+    base := defaultConfig()
+    var styleMode StyleMode
+    if strings.EqualFold(*styleFlag, "auto") {
+        inf, err := inferStyle(flag.Args())
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Failed to infer style: %v\n", err)
+            os.Exit(1)
+        }
+        printInference(os.Stderr, inf, flag.NArg())
+        styleMode = inf.Style
+        inf.apply(base)
+    } else {
+        mode, err := parseStyle(*styleFlag)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+            os.Exit(1)
+        }
+        styleMode = mode
+        styleMode.applyDefaults(base)
+    }
 
-    styleMode, err := parseStyle(*styleFlag)
+    cfg, err := loadConfig(*configFlag, base)
     if err != nil {
-        fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+        fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
         os.Exit(1)
     }
-
-    raw, err := os.ReadFile(filename)
+    if *stdFlag != "" {
+        cfg.Std = *stdFlag
+    }
+    std, err := parseStd(cfg.Std)
     if err != nil {
-        fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", filename, err)
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
         os.Exit(1)
     }
-    lines := strings.Split(string(raw), "\n")
+    std.apply()
+
+    var summaries []fileSummary
+    totalErrors, totalWarnings := 0, 0
+    for _, filename := range flag.Args() {
+        if *fixFlag {
+            fixed, err := fixFile(filename, cfg)
+            if err != nil {
+                fmt.Fprintf(os.Stderr, "Failed to fix %s: %v\n", filename, err)
+                os.Exit(1)
+            }
+            if fixed {
+                fmt.Printf("Fixed file banner in %s\n", filename)
+            }
+        }
 
-    errs := make([]StyleError, 0, 1000)
+        raw, err := os.ReadFile(filename)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", filename, err)
+            os.Exit(1)
+        }
+        lines := strings.Split(string(raw), "\n")
 
-    styleErrs, err := LintFile(filename, styleMode)
-    if err != nil {
-        if errors.Is(err, os.ErrNotExist) {
-            fmt.Fprintf(os.Stderr, "File not found: %s\n", filename)
-        } else {
-            fmt.Fprintf(os.Stderr, "Failed to process %s: %v\n", filename, err)
+        styleErrs, err := LintFile(filename, styleMode, cfg)
+        if err != nil {
+            if errors.Is(err, os.ErrNotExist) {
+                fmt.Fprintf(os.Stderr, "File not found: %s\n", filename)
+            } else {
+                fmt.Fprintf(os.Stderr, "Failed to process %s: %v\n", filename, err)
+            }
+            os.Exit(1)
+        }
+        uniqueErrs := uniqueStyleErrors(styleErrs)
+
+        if *reportFlag == "summary" {
+            summaries = append(summaries, summarizeFile(filename, lines, uniqueErrs))
+            continue
         }
+        nErrors, nWarnings := printFindings(filename, lines, uniqueErrs, cfg.Indent.TabWidth)
+        totalErrors += nErrors
+        totalWarnings += nWarnings
+    }
+
+    if *reportFlag == "summary" {
+        printSummary(summaries)
+        for _, s := range summaries {
+            totalErrors += s.Errors
+            totalWarnings += s.Warnings
+        }
+    } else if flag.NArg() > 1 {
+        fmt.Printf("%sChecked %d file(s): %s%d error(s)%s & %s%d warning(s)%s\n",
+            TitleCol, flag.NArg(),
+            ErrorFg, totalErrors, Reset,
+            WarningFg, totalWarnings, Reset,
+        )
+    }
+
+    if totalErrors+totalWarnings > 0 {
         os.Exit(1)
     }
-    errs = append(errs, styleErrs...)
+}
 
+func uniqueStyleErrors(errs []StyleError) []StyleError {
     seen := make(map[string]bool, len(errs))
     uniqueErrs := make([]StyleError, 0, len(errs))
     for _, e := range errs {
@@ -819,10 +2100,13 @@ func main() {
             uniqueErrs = append(uniqueErrs, e)
         }
     }
+    return uniqueErrs
+}
 
+func printFindings(filename string, lines []string, uniqueErrs []StyleError, tabWidth int) (int, int) {
     if len(uniqueErrs) == 0 {
         fmt.Printf("No style issues found in %s\n", filename)
-        os.Exit(0)
+        return 0, 0
     }
 
     totalErrors, totalWarnings := 0, 0
@@ -849,7 +2133,11 @@ func main() {
             levelColor, e.Level, Reset,
             LetterCol, e.Message, Reset,
         )
-        fmt.Printf("%s%s:%d:%d%s\n", LineNumCol, filename, e.LineNum, e.Start+1, Reset)
+        col := e.Start + 1
+        if e.LineNum >= 1 && e.LineNum <= len(lines) {
+            col = displayColumn(lines[e.LineNum-1], e.Start, tabWidth)
+        }
+        fmt.Printf("%s%s:%d:%d%s\n", LineNumCol, filename, e.LineNum, col, Reset)
         printContext(lines, e)
         fmt.Println()
     }
@@ -862,39 +2150,144 @@ func main() {
     )
     fmt.Printf("%s------------------------------------------------------------------%s\n\n", LineNumCol, Reset)
 
-    os.Exit(1)
+    return totalErrors, totalWarnings
 }
 
 /** ===============================================================
  *                   F I L E  F U N C T I O N
  * ================================================================ */
 func parseStyle(s string) (StyleMode, error) {
-    switch strings.ToLower(s) {
-    case "kr":
-        return StyleKR, nil
-    case "allman":
-        return StyleAllman, nil
-    default:
-        return 0, fmt.Errorf("invalid style: %q (use \"kr\" or \"allman\")", s)
+    if mode, ok := styleModes[strings.ToLower(s)]; ok {
+        return mode, nil
     }
+    return 0, fmt.Errorf("invalid style: %q (use \"kr\", \"allman\", \"stroustrup\", \"linux\", \"gnu\" or \"whitesmiths\")", s)
 }
 
-func (ctx *FileContext) ProcessIncludes() {
-    errs := processIncludes(ctx.Lines, ctx.Filename)
-    ctx.Errors = append(ctx.Errors, errs...)
+func (s StyleMode) String() string {
+    return styleNames[s]
 }
 
-func (ctx *FileContext) CheckEOFNewline() {
-    checkEOFNewline(ctx.Raw, &ctx.Errors)
+func (s StyleMode) controlBraceOwnLine() bool {
+    return s == StyleAllman || s == StyleGNU || s == StyleWhitesmiths
 }
 
-func (ctx *FileContext) CheckHeaderGuard() {
-    checkHeaderGuard(ctx.Lines, ctx.Filename, &ctx.Errors)
+func (s StyleMode) cuddledElse() bool {
+    return s == StyleKR || s == StyleLinux
 }
 
-func (ctx *FileContext) CheckStyle() {
-    styleErrs := checkStyle(ctx.Lines, ctx.Style)
-    ctx.Errors = append(ctx.Errors, styleErrs...)
+func (s StyleMode) braceIndent(step int, fileScope bool) int {
+    switch {
+    case s == StyleWhitesmiths:
+        return step
+    case s == StyleGNU && !fileScope:
+        return step
+    }
+    return 0
+}
+
+func (s StyleMode) bodyIndent(step int) int {
+    if s == StyleWhitesmiths {
+        return 0
+    }
+    return step
+}
+
+func (s StyleMode) applyDefaults(cfg *Config) {
+    switch s {
+    case StyleLinux:
+        cfg.Indent = IndentConfig{Style: "tabs", Width: 8, TabWidth: 8}
+    case StyleGNU:
+        cfg.Indent.Style, cfg.Indent.Width = "spaces", 2
+    case StyleWhitesmiths:
+        cfg.Indent.Style, cfg.Indent.Width = "spaces", 4
+    }
+}
+
+func (ctx *FileContext) ProcessIncludes() {
+    errs := processIncludes(ctx.Lines, ctx.Filename)
+    ctx.Errors = append(ctx.Errors, errs...)
+}
+
+func (ctx *FileContext) CheckEOFNewline() {
+    checkEOFNewline(ctx.Raw, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckHeaderGuard() {
+    checkHeaderGuard(ctx.Lines, ctx.Filename, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckStyle() {
+    styleErrs := checkStyle(ctx.Lines, ctx.Style, ctx.Config, ctx.Source)
+    ctx.Errors = append(ctx.Errors, styleErrs...)
+}
+
+func (ctx *FileContext) CheckUnicode() {
+    checkUnicode(ctx.Lines, &ctx.Config.Unicode, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckSecrets() {
+    checkSecrets(ctx.Source, &ctx.Config.Secrets, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckMacroHygiene() {
+    checkMacroHygiene(ctx.Source, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckNaming() {
+    checkNaming(ctx.Source, ctx.Filename, &ctx.Config.Naming, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckAPIConsistency() {
+    checkAPIConsistency(ctx.Filename, ctx.Source, &ctx.Config.API, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckHeaderRules() {
+    checkHeaderRules(ctx.Filename, ctx.Source, &ctx.Config.Header, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckDoxygen() {
+    checkDoxygen(ctx.Filename, ctx.Source, &ctx.Config.Doxygen, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckBanner() {
+    checkBanner(ctx.Filename, ctx.Lines, ctx.Source, &ctx.Config.Banner, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckWrapping() {
+    checkWrapping(ctx.Lines, ctx.Source, ctx.Config, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckPointerStyle() {
+    checkPointerStyle(ctx.Source, &ctx.Config.Pointer, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckStandard() {
+    std, _ := parseStd(ctx.Config.Std)
+    checkStandard(ctx.Source, std, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckMisra() {
+    checkMisra(ctx.Source, &ctx.Config.Misra, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckPowerOfTen() {
+    checkPowerOfTen(ctx.Source, &ctx.Config.PowerOfTen, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckMagicNumbers() {
+    checkMagicNumbers(ctx.Source, &ctx.Config.MagicNumbers, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckSwitches() {
+    checkSwitches(ctx.Filename, ctx.Source, &ctx.Config.Switch, &ctx.Config.Misra, ctx.Config.API.HeaderDirs, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckCert() {
+    checkCert(ctx.Source, &ctx.Config.Cert, &ctx.Errors)
+}
+
+func (ctx *FileContext) CheckMetrics() {
+    checkMetrics(ctx.Filename, ctx.Source, &ctx.Config.Metrics, &ctx.Errors)
 }
 
 func preprocessCaseBraces(lines []string) []string {
@@ -911,7 +2304,7 @@ func preprocessCaseBraces(lines []string) []string {
     return out
 }
 
-func LintFile(filename string, style StyleMode) ([]StyleError, error) {
+func LintFile(filename string, style StyleMode, cfg *Config) ([]StyleError, error) {
     raw, err := os.ReadFile(filename)
     if err != nil {
         return nil, err
@@ -924,6 +2317,8 @@ func LintFile(filename string, style StyleMode) ([]StyleError, error) {
         Lines:    lines,
         Raw:      raw,
         Style:    style,
+        Config:   cfg,
+        Source:   parseSource(lines),
         Errors:   nil,
     }
 
@@ -931,6 +2326,23 @@ func LintFile(filename string, style StyleMode) ([]StyleError, error) {
     ctx.CheckEOFNewline()
     ctx.CheckHeaderGuard()
     ctx.CheckStyle()
+    ctx.CheckUnicode()
+    ctx.CheckSecrets()
+    ctx.CheckMacroHygiene()
+    ctx.CheckNaming()
+    ctx.CheckAPIConsistency()
+    ctx.CheckHeaderRules()
+    ctx.CheckDoxygen()
+    ctx.CheckBanner()
+    ctx.CheckMetrics()
+    ctx.CheckWrapping()
+    ctx.CheckPointerStyle()
+    ctx.CheckSwitches()
+    ctx.CheckMagicNumbers()
+    ctx.CheckStandard()
+    ctx.CheckMisra()
+    ctx.CheckPowerOfTen()
+    ctx.CheckCert()
 
     return ctx.Errors, nil
 }
@@ -955,9 +2367,15 @@ func processIncludes(lines []string, filename string) []StyleError {
     }
     var sysIncludes, projIncludes []includeEntry
 
+    base := filepath.Base(filename)
+    ownHeader := strings.TrimSuffix(base, filepath.Ext(base)) + ".h"
+
     for idx, l := range lines {
         if m := reInclude.FindStringSubmatch(l); m != nil {
             entry := includeEntry{inc: m[1], line: idx + 1}
+            if !isHeaderFile(filename) && filepath.Base(strings.Trim(m[1], `"`)) == ownHeader {
+                continue
+            }
             if strings.HasPrefix(m[1], "<") {
                 sysIncludes = append(sysIncludes, entry)
             } else {
@@ -973,6 +2391,7 @@ func processIncludes(lines []string, filename string) []StyleError {
                     Length:  len(m[1]),
                     Message: FormatMessage(ErrRecursiveInclusion, m[1]),
                     Level:   FormatErrorLevel(ErrRecursiveInclusion),
+                    Code:    ErrRecursiveInclusion,
                 })
             }
         }
@@ -988,9 +2407,10 @@ func processIncludes(lines []string, filename string) []StyleError {
             errs = append(errs, StyleError{
                 LineNum: firstProj.line,
                 Start:   start,
-                Length:  utf8.RuneCountInString(full[start:]),
+                Length:  len(full[start:]),
                 Message: FormatMessage(ErrSysBeforeProjIncludesOrder),
                 Level:   FormatErrorLevel(ErrSysBeforeProjIncludesOrder),
+                Code:    ErrSysBeforeProjIncludesOrder,
             })
         }
     }
@@ -1013,9 +2433,10 @@ func processIncludes(lines []string, filename string) []StyleError {
         errs = append(errs, StyleError{
             LineNum: bad.line,
             Start:   start,
-            Length:  utf8.RuneCountInString(full[start:]),
+            Length:  len(full[start:]),
             Message: FormatMessage(ErrSysIncludesNotSorted),
             Level:   FormatErrorLevel(ErrSysIncludesNotSorted),
+            Code:    ErrSysIncludesNotSorted,
         })
     }
 
@@ -1027,9 +2448,10 @@ func processIncludes(lines []string, filename string) []StyleError {
         errs = append(errs, StyleError{
             LineNum: bad.line,
             Start:   start,
-            Length:  utf8.RuneCountInString(full[start:]),
+            Length:  len(full[start:]),
             Message: FormatMessage(ErrProjIncludesNotSorted),
             Level:   FormatErrorLevel(ErrProjIncludesNotSorted),
+            Code:    ErrProjIncludesNotSorted,
         })
     }
 
@@ -1072,6 +2494,7 @@ func checkPragmaOnce(
                 Length:  len("#pragma once"),
                 Message: FormatMessage(ErrPragmaOnceAndIncludeGuard),
                 Level:   FormatErrorLevel(ErrPragmaOnceAndIncludeGuard),
+                Code:    ErrPragmaOnceAndIncludeGuard,
             })
         }
     }
@@ -1122,8 +2545,14 @@ func FormatErrorLevel(code ErrorCode) string {
 /** ===============================================================
  *          C H E C K  -  S T Y L E  F U N C T I O N
  * ================================================================ */
-func checkStyle(lines []string, style StyleMode) []StyleError {
-    const maskRune = '\uFFFD'
+func checkStyle(lines []string, style StyleMode, cfg *Config, model *sourceModel) []StyleError {
+    const maskRune = '`'
+
+    ind := &cfg.Indent
+    step := ind.step()
+    continuation := continuationLines(model)
+    logical := logicalLines(lines, model)
+    directiveCont := directiveContinuations(model)
 
     var errs []StyleError
     var typeStack []typeCtx
@@ -1133,18 +2562,22 @@ func checkStyle(lines []string, style StyleMode) []StyleError {
     var caseEndLine = -1
     var caseIndentLevel = 0
 
-    indentStack := []int{0}
+    indentStack := []indentFrame{{Line: -1}}
     pendingTypeDecl := false
     pendingTypedef := false
     inBlockComment := false
     inParamBlock := false
-    nextIndent := -1
+    nextIndent := indentFrame{Col: -1}
     indentForStack := 0
-    paramIndent := 0
     typeKind := ""
 
     blankCountTracker := make([]int, len(lines))
 
+    if ind.AlignBackslashes {
+        checkBackslashAlignment(lines, model, ind, &errs)
+    }
+
+    ptrStars := pointerStars(model)
     pointerRegexes := []*regexp.Regexp{
         rePtrDecl,
         reTypedefFuncPtr,
@@ -1159,9 +2592,13 @@ func checkStyle(lines []string, style StyleMode) []StyleError {
         trim := strings.TrimSpace(line)
         codeOnly := line
 
-        indent := getIndent(line)
+        indent := getIndent(line, ind.TabWidth)
 
-        checkLineLength(i, line, &errs)
+        checkLineLength(i, line, inBlockComment, cfg, &errs)
+
+        if !inBlockComment {
+            checkIndentChars(line, trim, i, ind, &errs)
+        }
 
         checkConsecutiveBlankLines(i, lines, &blankCountTracker, &errs)
 
@@ -1193,16 +2630,19 @@ func checkStyle(lines []string, style StyleMode) []StyleError {
             continue
         }
 
+        if logical[i] != nil || directiveCont[i] {
+            codeOnly = trimContinuation(codeOnly)
+        }
+
         checkSemicolonSpace(i, codeOnly, &errs)
-        checkNonASCII(i, line, &errs)
 
-        if handleClosingElse(trim, &indentStack) {
+        checkElsePlacement(style, trim, lines, i, line, &errs)
+
+        if handleClosingElse(trim, i, &indentStack, step) {
             continue
         }
 
-        checkKRElse(style, trim, lines, i, line, &errs)
-
-        if handleIncludeIndentation(trim, indent, i, &errs) {
+        if handleIncludeIndentation(trim, line, indent, i, &errs) {
             continue
         }
 
@@ -1211,19 +2651,33 @@ func checkStyle(lines []string, style StyleMode) []StyleError {
         checkBadCommaSpace(codeOnly, i, &errs)
         checkMultipleSpaces(codeOnly, i, &errs)
 
-        checkPointerFormatting(codeOnly, i, reCombinedPtr, reBadPtrCast, &errs)
+        checkPointerCast(codeOnly, i, reBadPtrCast, &errs)
 
-        checkMacroBodyNoSpace(line, i, &errs)
-
-        checkMacroDefIdentifiers(line, codeOnly, i, &errs)
+        if lg := logical[i]; lg != nil {
+            var macroErrs []StyleError
+            checkMacroBodyNoSpace(lg.Text, i, &macroErrs)
+            checkMacroDefIdentifiers(lg.Text, lg.Code, i, &macroErrs)
+            checkFuncMacroBodyParenthesized(lg.Text, i, &macroErrs)
+            errs = append(errs, lg.remap(macroErrs)...)
+        } else {
+            checkMacroBodyNoSpace(line, i, &errs)
+            checkMacroDefIdentifiers(line, codeOnly, i, &errs)
+        }
 
-        checkOperatorSpacing(codeOnly, trim, i, pointerRegexes, &errs)
+        checkOperatorSpacing(codeOnly, trim, i, pointerRegexes, ptrStars[i], &errs)
 
         checkKeywordSpaceBeforeParen(codeOnly, line, i, &errs)
 
-        checkMagicNumberUsage(codeOnly, trim, i, &errs)
+        if directiveCont[i] {
+            checkTrailingWhitespace(line, i, &errs)
+            checkFuncCallSpace(line, i+1, &errs)
+            checkTernarySpacing(codeOnly, i, &errs)
+            checkAllocCallMustBeCast(codeOnly, i, &errs)
+            checkUnsafeFunctions(codeOnly, i+1, &errs)
+            continue
+        }
 
-        if checkParamBlock(line, trim, indent, i, &inParamBlock, &paramIndent, &errs) {
+        if checkParamBlock(line, trim, i, &inParamBlock, &errs) {
             continue
         }
 
@@ -1237,29 +2691,31 @@ func checkStyle(lines []string, style StyleMode) []StyleError {
             continue
         }
 
-        if !inParamBlock {
-            checkFuncDeclName(codeOnly, line, i, &errs)
+        if style != StyleGNU {
+            checkReturnTypeSameLine(lines, line, trim, i, &errs)
         }
-
-        checkPrevLineOnlyTypeFuncName(lines, line, i, &errs)
-
-        checkReturnTypeSameLine(lines, line, trim, i, &errs)
         checkFuncCallSpace(line, i+1, &errs)
 
-        checkCloseIndent(trim, codeOnly, &indentStack)
+        if strings.HasPrefix(trim, "#") {
+            checkDirectiveIndent(trim, line, indent, i, &errs)
+        } else if continuation[i] {
+            checkOpenBrace(i, trim, lines, indentForStack, step, style, &indentStack)
+        } else {
+            closed, _ := checkCloseIndent(trim, codeOnly, &indentStack)
 
-        if checkCaseBlock(trim, line, i, lines, indent, &indentStack, &caseIndentLevel, &caseEndLine, &errs) {
-            continue
-        }
+            if checkCaseBlock(trim, line, i, lines, indent, ind, &indentStack, &caseIndentLevel, &caseEndLine, &errs) {
+                continue
+            }
 
-        if checkIndentRules(i, trim, codeOnly, indent,
-            &indentStack, &nextIndent, &caseEndLine, &indentForStack, &errs) {
-            continue
-        }
+            if checkIndentRules(i, trim, codeOnly, indent, step, style, closed,
+                &indentStack, &nextIndent, &caseEndLine, &indentForStack, &errs) {
+                continue
+            }
 
-        checkOpenBrace(i, trim, lines, indentForStack, &indentStack)
+            checkOpenBrace(i, trim, lines, indentForStack, step, style, &indentStack)
 
-        checkControlStmtIndent(trim, codeOnly, indentForStack, &nextIndent)
+            checkControlStmtIndent(trim, codeOnly, i, indentForStack, step, &nextIndent)
+        }
 
         if isOnlyWhitespace(codeOnly) {
             continue
@@ -1288,15 +2744,11 @@ func checkStyle(lines []string, style StyleMode) []StyleError {
             continue
         }
 
-        checkDataStructureFields(ctx, trim, line, codeOnly, i, &errs)
         checkUninitializedDecls(ctx, codeOnly, line, i, &errs)
-        checkVarNameNotEndWithT(trim, codeOnly, line, i, &errs)
         checkMultipleVarDecl(inParamBlock, codeOnly, line, i, &errs)
-        checkTypedefFuncPtrName(codeOnly, line, i, &errs)
-        checkTypedefGenericName(codeOnly, line, i, &errs)
-        checkMacroNameScreamingSnake(codeOnly, line, i, &errs)
-        checkFuncMacroBodyParenthesized(line, i, &errs)
-        checkParamNamesSnakeCase(line, codeOnly, i, &errs)
+        if logical[i] == nil {
+            checkFuncMacroBodyParenthesized(line, i, &errs)
+        }
         checkTernarySpacing(codeOnly, i, &errs)
         checkFuncOpeningBraceOwnLine(line, codeOnly, i, &errs)
         checkAllmanBrace(style, line, codeOnly, i, &errs)
@@ -1328,6 +2780,7 @@ func processCommentRules(
             Length:  m[1] - m[0],
             Message: FormatMessage(WarnFoundTODOOrFIXME),
             Level:   FormatErrorLevel(WarnFoundTODOOrFIXME),
+            Code:    WarnFoundTODOOrFIXME,
         })
     }
 }
@@ -1357,6 +2810,7 @@ func checkUnsafeFunctions(
             Length:  len(name),
             Message: FormatMessage(WarnUseOfInsecureFunction, name, suggestion),
             Level:   FormatErrorLevel(WarnUseOfInsecureFunction),
+            Code:    WarnUseOfInsecureFunction,
         })
     }
 }
@@ -1413,6 +2867,7 @@ func checkConstPointerParams(
                     Length:  len(name),
                     Message: FormatMessage(WarnPointerNotModifiedMustBeConst, name, p),
                     Level:   FormatErrorLevel(WarnPointerNotModifiedMustBeConst),
+                    Code:    WarnPointerNotModifiedMustBeConst,
                 })
             }
         }
@@ -1485,6 +2940,7 @@ func checkHeaderGuard(
             Length:  0,
             Message: FormatMessage(ErrPragmaOnceAndIncludeGuard),
             Level:   FormatErrorLevel(ErrPragmaOnceAndIncludeGuard),
+            Code:    ErrPragmaOnceAndIncludeGuard,
         })
     }
 }
@@ -1508,24 +2964,54 @@ func checkEOFNewline(
         Length:  1,
         Message: FormatMessage(ErrFileMustEndWithNewline),
         Level:   FormatErrorLevel(ErrFileMustEndWithNewline),
+        Code:    ErrFileMustEndWithNewline,
     })
 }
 
 func checkLineLength(
     i int,
     line string,
+    inComment bool,
+    cfg *Config,
     errs *[]StyleError,
 ) {
-    const maxLineLength = 80
-    if l := utf8.RuneCountInString(line); l > maxLineLength {
-        *errs = append(*errs, StyleError{
-            LineNum: i + 1,
-            Start:   maxLineLength,
-            Length:  l - maxLineLength,
-            Message: FormatMessage(ErrLineLengthExceeded, maxLineLength, l),
-            Level:   FormatErrorLevel(ErrLineLengthExceeded),
-        })
+    ll := &cfg.LineLength
+    trim := strings.TrimSpace(line)
+    switch {
+    case ll.ExemptURLs && reURL.MatchString(line),
+        ll.ExemptIncludes && reIncludeLine.MatchString(trim),
+        ll.ExemptStrings && reStringOnlyLine.MatchString(trim):
+        return
+    }
+
+    width := displayColumn(line, len(line), cfg.Indent.TabWidth) - 1
+
+    code, limit := ErrorCode(ErrLineLengthExceeded), ll.Code
+    if inComment || strings.HasPrefix(trim, "//") || strings.HasPrefix(trim, "/*") {
+        code, limit = ErrCommentLineLengthExceeded, ll.Comment
+    }
+    if width <= limit {
+        if ll.Soft <= 0 || ll.Soft >= limit || width <= ll.Soft {
+            return
+        }
+        code, limit = WarnLineLengthSoftLimit, ll.Soft
+    }
+
+    start := len(line)
+    for k, ch := range line {
+        if displayColumn(line, k+utf8.RuneLen(ch), cfg.Indent.TabWidth)-1 > limit {
+            start = k
+            break
+        }
     }
+    *errs = append(*errs, StyleError{
+        LineNum: i + 1,
+        Start:   start,
+        Length:  len(line) - start,
+        Message: FormatMessage(code, limit, width),
+        Level:   FormatErrorLevel(code),
+        Code:    code,
+    })
 }
 
 func checkConsecutiveBlankLines(
@@ -1555,6 +3041,7 @@ func checkConsecutiveBlankLines(
                 Length:  0,
                 Message: FormatMessage(WarnTooManyBlankLinesConsecutively, (*errCount)[i]),
                 Level:   FormatErrorLevel(WarnTooManyBlankLinesConsecutively),
+                Code:    WarnTooManyBlankLinesConsecutively,
             })
         }
     } else {
@@ -1574,6 +3061,7 @@ func checkTrailingBlankLinesIfEOF(
             Length:  0,
             Message: FormatMessage(WarnFileEndsWithExtraBlankLines, blankCount),
             Level:   FormatErrorLevel(WarnFileEndsWithExtraBlankLines),
+            Code:    WarnFileEndsWithExtraBlankLines,
         })
     }
 }
@@ -1613,6 +3101,7 @@ func checkBlankLinesAfterFunction(
                         Length:  0,
                         Message: FormatMessage(ErrMissingBlankLineAfterFunction),
                         Level:   FormatErrorLevel(ErrMissingBlankLineAfterFunction),
+                        Code:    ErrMissingBlankLineAfterFunction,
                     })
                 } else if blankCount > 1 {
                     *errs = append(*errs, StyleError{
@@ -1621,6 +3110,7 @@ func checkBlankLinesAfterFunction(
                         Length:  0,
                         Message: FormatMessage(WarnTooManyBlankLinesBetweenFunctions, blankCount),
                         Level:   FormatErrorLevel(WarnTooManyBlankLinesBetweenFunctions),
+                        Code:    WarnTooManyBlankLinesBetweenFunctions,
                     })
                 }
             }
@@ -1643,28 +3133,11 @@ func checkSemicolonSpace(
             Length:  loc[1] - loc[0],
             Message: FormatMessage(ErrNoSpaceBeforeSemicolon),
             Level:   FormatErrorLevel(ErrNoSpaceBeforeSemicolon),
+            Code:    ErrNoSpaceBeforeSemicolon,
         })
     }
 }
 
-func checkNonASCII(
-    i int,
-    line string,
-    errs *[]StyleError,
-) {
-    for idx, ch := range line {
-        if ch > 127 {
-            *errs = append(*errs, StyleError{
-                LineNum: i + 1,
-                Start:   idx,
-                Length:  1,
-                Message: FormatMessage(WarnNonASCIICharacter, ch),
-                Level:   FormatErrorLevel(WarnNonASCIICharacter),
-            })
-        }
-    }
-}
-
 func handleInBlockComment(
     codeOnly *string,
     i int,
@@ -1745,7 +3218,7 @@ func shouldContinueIfOnlyMask(codeOnly *string, maskRune rune) bool {
     return trimmed == ""
 }
 
-func handleClosingElse(trim string, indentStack *[]int) bool {
+func handleClosingElse(trim string, i int, indentStack *[]indentFrame, step int) bool {
     if !strings.HasPrefix(trim, "} else {") {
         return false
     }
@@ -1753,12 +3226,12 @@ func handleClosingElse(trim string, indentStack *[]int) bool {
         *indentStack = (*indentStack)[:len(*indentStack)-1]
     }
 
-    indentForStack := (*indentStack)[len(*indentStack)-1]
-    *indentStack = append(*indentStack, indentForStack+2)
+    indentForStack := (*indentStack)[len(*indentStack)-1].Col
+    *indentStack = append(*indentStack, indentFrame{Col: indentForStack + step, Owner: "else", Line: i})
     return true
 }
 
-func checkKRElse(
+func checkElsePlacement(
     style StyleMode,
     trim string,
     lines []string,
@@ -1766,7 +3239,20 @@ func checkKRElse(
     line string,
     errs *[]StyleError,
 ) {
-    if style != StyleKR || trim != "else {" || i == 0 {
+    if !style.cuddledElse() {
+        if reCuddledElse.MatchString(trim) {
+            *errs = append(*errs, StyleError{
+                LineNum: i + 1,
+                Start:   strings.Index(line, "else"),
+                Length:  len("else"),
+                Message: FormatMessage(ErrElseMustBeOnOwnLine, style),
+                Level:   FormatErrorLevel(ErrElseMustBeOnOwnLine),
+                Code:    ErrElseMustBeOnOwnLine,
+            })
+        }
+        return
+    }
+    if trim != "else {" || i == 0 {
         return
     }
     if strings.TrimSpace(lines[i-1]) == "}" {
@@ -1775,19 +3261,20 @@ func checkKRElse(
             LineNum: i + 1,
             Start:   pos,
             Length:  len("else"),
-            Message: FormatMessage(ErrElseMustBeOnSameLineAsClosingBrace),
+            Message: FormatMessage(ErrElseMustBeOnSameLineAsClosingBrace, style),
             Level:   FormatErrorLevel(ErrElseMustBeOnSameLineAsClosingBrace),
+            Code:    ErrElseMustBeOnSameLineAsClosingBrace,
         })
     }
 }
 
-func getIndent(line string) int {
+func getIndent(line string, tabWidth int) int {
     indent := 0
     for _, ch := range line {
         if ch == ' ' {
             indent++
         } else if ch == '\t' {
-            indent += 2
+            indent += tabWidth - indent%tabWidth
         } else {
             break
         }
@@ -1795,8 +3282,123 @@ func getIndent(line string) int {
     return indent
 }
 
+func leadingWhitespace(line string) string {
+    return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+func displayColumn(line string, start, tabWidth int) int {
+    col := 0
+    for i, ch := range line {
+        if i >= start {
+            break
+        }
+        if ch == '\t' {
+            col += tabWidth - col%tabWidth
+        } else {
+            col++
+        }
+    }
+    return col + 1
+}
+
+func constructText(t string) string {
+    t = strings.Join(strings.Fields(t), " ")
+    if r := []rune(t); len(r) > 40 {
+        t = string(r[:37]) + "..."
+    }
+    return t
+}
+
+func describeFrame(f indentFrame) string {
+    if f.Line < 0 {
+        return "the file scope"
+    }
+    return fmt.Sprintf("'%s' (line %d)", f.Owner, f.Line+1)
+}
+
+func blockOwner(lines []string, i int) indentFrame {
+    t := strings.TrimSpace(lines[i])
+    if k := strings.Index(t, "{"); k >= 0 {
+        t = strings.TrimSpace(t[:k])
+    }
+    line := i
+    for j := i - 1; t == "" && j >= 0; j-- {
+        t = strings.TrimSpace(lines[j])
+        line = j
+    }
+    return indentFrame{Owner: constructText(t), Line: line}
+}
+
+func checkDirectiveIndent(
+    trim, line string,
+    indent, i int,
+    errs *[]StyleError,
+) {
+    if indent == 0 {
+        return
+    }
+    name := trim
+    if m := reDirectiveName.FindString(trim); m != "" {
+        name = m
+    }
+    *errs = append(*errs, StyleError{
+        LineNum: i + 1,
+        Start:   0,
+        Length:  len(leadingWhitespace(line)),
+        Message: FormatMessage(ErrDirectiveIndent, name, indent),
+        Level:   FormatErrorLevel(ErrDirectiveIndent),
+        Code:    ErrDirectiveIndent,
+    })
+}
+
+func checkIndentChars(
+    line, trim string,
+    i int,
+    ind *IndentConfig,
+    errs *[]StyleError,
+) {
+    ws := leadingWhitespace(line)
+    if ws == "" || trim == "" {
+        return
+    }
+    hasTab := strings.Contains(ws, "\t")
+    hasSpace := strings.Contains(ws, " ")
+
+    code := ErrorCode(-1)
+    switch ind.Style {
+    case "spaces":
+        if hasTab && hasSpace {
+            code = ErrMixedIndentation
+        } else if hasTab {
+            code = ErrIndentTabsNotAllowed
+        }
+    case "tabs":
+        if hasTab && hasSpace {
+            code = ErrMixedIndentation
+        } else if hasSpace {
+            code = ErrIndentSpacesNotAllowed
+        }
+    case "smart_tabs":
+        if strings.Contains(ws, " \t") {
+            code = ErrMixedIndentation
+        }
+    }
+    if code < 0 {
+        return
+    }
+
+    *errs = append(*errs, StyleError{
+        LineNum: i + 1,
+        Start:   0,
+        Length:  len(ws),
+        Message: FormatMessage(code),
+        Level:   FormatErrorLevel(code),
+        Code:    code,
+    })
+}
+
 func handleIncludeIndentation(
-    trim string,
+    trim, line string,
     indent int,
     i int,
     errs *[]StyleError,
@@ -1808,9 +3410,10 @@ func handleIncludeIndentation(
         *errs = append(*errs, StyleError{
             LineNum: i + 1,
             Start:   0,
-            Length:  indent,
+            Length:  len(leadingWhitespace(line)),
             Message: FormatMessage(ErrIncludeDirectiveIndentation),
             Level:   FormatErrorLevel(ErrIncludeDirectiveIndentation),
+            Code:    ErrIncludeDirectiveIndentation,
         })
     }
     return true
@@ -1829,6 +3432,7 @@ func checkBadParenSpace(
                 Length:  loc[1] - loc[0],
                 Message: FormatMessage(ErrNoSpaceAllowedInsideParentheses),
                 Level:   FormatErrorLevel(ErrNoSpaceAllowedInsideParentheses),
+                Code:    ErrNoSpaceAllowedInsideParentheses,
             })
         }
     }
@@ -1847,6 +3451,7 @@ func checkBadBracketSpace(
                 Length:  loc[1] - loc[0],
                 Message: FormatMessage(ErrNoSpaceAllowedAroundBrackets),
                 Level:   FormatErrorLevel(ErrNoSpaceAllowedAroundBrackets),
+                Code:    ErrNoSpaceAllowedAroundBrackets,
             })
         }
     }
@@ -1865,6 +3470,7 @@ func checkBadCommaSpace(
                 Length:  loc[1] - loc[0],
                 Message: FormatMessage(ErrCommaMustBeSurroundedBySingleSpace),
                 Level:   FormatErrorLevel(ErrCommaMustBeSurroundedBySingleSpace),
+                Code:    ErrCommaMustBeSurroundedBySingleSpace,
             })
         }
     }
@@ -1887,28 +3493,17 @@ func checkMultipleSpaces(
             Length:  length,
             Message: FormatMessage(ErrMultipleConsecutiveSpaces),
             Level:   FormatErrorLevel(ErrMultipleConsecutiveSpaces),
+            Code:    ErrMultipleConsecutiveSpaces,
         })
     }
 }
 
-func checkPointerFormatting(
+func checkPointerCast(
     codeOnly string,
     lineNum int,
-    reCombinedPtr, reBadPtrCast *regexp.Regexp,
+    reBadPtrCast *regexp.Regexp,
     errs *[]StyleError,
 ) {
-    if locs := reCombinedPtr.FindAllStringIndex(codeOnly, -1); locs != nil {
-        for _, loc := range locs {
-            *errs = append(*errs, StyleError{
-                LineNum: lineNum + 1,
-                Start:   loc[0],
-                Length:  loc[1] - loc[0],
-                Message: FormatMessage(ErrPointerFormattingRules),
-                Level:   FormatErrorLevel(ErrPointerFormattingRules),
-            })
-        }
-    }
-
     if locs := reBadPtrCast.FindAllStringIndex(codeOnly, -1); locs != nil {
         for _, loc := range locs {
             *errs = append(*errs, StyleError{
@@ -1917,6 +3512,7 @@ func checkPointerFormatting(
                 Length:  loc[1] - loc[0],
                 Message: FormatMessage(ErrPointerCastMustBeAttached),
                 Level:   FormatErrorLevel(ErrPointerCastMustBeAttached),
+                Code:    ErrPointerCastMustBeAttached,
             })
         }
     }
@@ -1935,6 +3531,7 @@ func checkMacroBodyNoSpace(
             Length:  1,
             Message: FormatMessage(ErrMacroBodyMustHaveSpaceAfterParams),
             Level:   FormatErrorLevel(ErrMacroBodyMustHaveSpaceAfterParams),
+            Code:    ErrMacroBodyMustHaveSpaceAfterParams,
         })
     }
 }
@@ -1945,9 +3542,9 @@ func checkMacroDefIdentifiers(
     errs *[]StyleError,
 ) {
     if m := reMacroDef.FindStringSubmatchIndex(codeOnly); m != nil {
-        macroName := line[m[2]:m[3]]
-        rawParams := line[m[4]:m[5]]
-        macroBody := line[m[6]:m[7]]
+        macroName := codeOnly[m[2]:m[3]]
+        rawParams := codeOnly[m[4]:m[5]]
+        macroBody := codeOnly[m[6]:m[7]]
 
         params := []string{}
         for _, p := range strings.Split(rawParams, ",") {
@@ -1960,6 +3557,7 @@ func checkMacroDefIdentifiers(
                     Length:  len(name),
                     Message: FormatMessage(ErrMacroParamMustBeSnakeCase, name),
                     Level:   FormatErrorLevel(ErrMacroParamMustBeSnakeCase),
+                    Code:    ErrMacroParamMustBeSnakeCase,
                 })
             }
             params = append(params, name)
@@ -1985,6 +3583,7 @@ func checkMacroDefIdentifiers(
                     Length:  len(ident),
                     Message: FormatMessage(ErrMacroBodyIdentifierMustBeSnakeCase, ident),
                     Level:   FormatErrorLevel(ErrMacroBodyIdentifierMustBeSnakeCase),
+                    Code:    ErrMacroBodyIdentifierMustBeSnakeCase,
                 })
             }
         }
@@ -1996,6 +3595,7 @@ func checkOperatorSpacing(
     trim string,
     lineNum int,
     pointerRegexes []*regexp.Regexp,
+    ptrStars map[int]bool,
     errs *[]StyleError,
 ) {
     var pointerRanges [][]int
@@ -2031,7 +3631,7 @@ func checkOperatorSpacing(
             continue
         }
 
-        if op == "*" && inPtrRange(startIdx, endIdx) {
+        if op == "*" && (ptrStars[startIdx] || inPtrRange(startIdx, endIdx)) {
             continue
         }
 
@@ -2043,6 +3643,7 @@ func checkOperatorSpacing(
                 Length:  len(op),
                 Message: FormatMessage(ErrOperatorMustHaveSpaceBefore, op),
                 Level:   FormatErrorLevel(ErrOperatorMustHaveSpaceBefore),
+                Code:    ErrOperatorMustHaveSpaceBefore,
             })
         }
 
@@ -2054,6 +3655,7 @@ func checkOperatorSpacing(
                 Length:  len(op),
                 Message: FormatMessage(ErrOperatorMustHaveSpaceAfter, op),
                 Level:   FormatErrorLevel(ErrOperatorMustHaveSpaceAfter),
+                Code:    ErrOperatorMustHaveSpaceAfter,
             })
         }
     }
@@ -2073,38 +3675,15 @@ func checkKeywordSpaceBeforeParen(
             Length:  len(kw),
             Message: FormatMessage(ErrKeywordMustHaveSpaceBeforeParen),
             Level:   FormatErrorLevel(ErrKeywordMustHaveSpaceBeforeParen),
-        })
-    }
-}
-
-func checkMagicNumberUsage(
-    codeOnly string,
-    trim string,
-    lineNum int,
-    errs *[]StyleError,
-) {
-    for _, loc := range reMagicNumber.FindAllStringIndex(codeOnly, -1) {
-        num := codeOnly[loc[0]:loc[1]]
-
-        if reEnumElement.MatchString(codeOnly) || strings.HasPrefix(trim, "#define") {
-            continue
-        }
-
-        *errs = append(*errs, StyleError{
-            LineNum: lineNum + 1,
-            Start:   loc[0],
-            Length:  loc[1] - loc[0],
-            Message: FormatMessage(WarnMagicNumberDetected, num),
-            Level:   FormatErrorLevel(WarnMagicNumberDetected),
+            Code:    ErrKeywordMustHaveSpaceBeforeParen,
         })
     }
 }
 
 func checkParamBlock(
     line, trim string,
-    indent, lineNum int,
+    lineNum int,
     inParamBlock *bool,
-    paramIndent *int,
     errs *[]StyleError,
 ) bool {
     if !*inParamBlock && strings.Contains(line, "(") && strings.HasSuffix(trim, ",") {
@@ -2114,8 +3693,6 @@ func checkParamBlock(
             loc = reFuncHeader.FindStringSubmatchIndex(line)
         }
         if loc != nil {
-            name := line[loc[2]:loc[3]]
-
             if loc[3] < len(line) && line[loc[3]] == ' ' {
                 *errs = append(*errs, StyleError{
                     LineNum: lineNum + 1,
@@ -2123,39 +3700,16 @@ func checkParamBlock(
                     Length:  1,
                     Message: FormatMessage(ErrFuncNameNoSpaceBeforeParen),
                     Level:   FormatErrorLevel(ErrFuncNameNoSpaceBeforeParen),
-                })
-            }
-
-            if name != "main" && !reFunctionName.MatchString(name) {
-                pos := strings.Index(line, name)
-                *errs = append(*errs, StyleError{
-                    LineNum: lineNum + 1,
-                    Start:   pos,
-                    Length:  len(name),
-                    Message: FormatMessage(ErrFunctionNameMustBeModuleCamelCase, name),
-                    Level:   FormatErrorLevel(ErrFunctionNameMustBeModuleCamelCase),
+                    Code:    ErrFuncNameNoSpaceBeforeParen,
                 })
             }
         }
 
-        if pp := strings.Index(line, "("); pp >= 0 {
-            *paramIndent = ((pp + 2) / 2) * 2
-        }
         *inParamBlock = true
         return true
     }
 
     if *inParamBlock {
-        if indent != *paramIndent {
-            *errs = append(*errs, StyleError{
-                LineNum: lineNum + 1,
-                Start:   0,
-                Length:  indent,
-                Message: FormatMessage(ErrParameterLineWrongIndent, *paramIndent, indent),
-                Level:   FormatErrorLevel(ErrParameterLineWrongIndent),
-            })
-        }
-
         if strings.Contains(trim, ")") {
             *inParamBlock = false
             return true
@@ -2167,6 +3721,7 @@ func checkParamBlock(
                 Length:  1,
                 Message: FormatMessage(ErrParameterLineMustEndWithComma),
                 Level:   FormatErrorLevel(ErrParameterLineMustEndWithComma),
+                Code:    ErrParameterLineMustEndWithComma,
             })
         }
         return true
@@ -2200,6 +3755,7 @@ func checkBlankLine(
                 Length:  indent,
                 Message: FormatMessage(ErrBlankLineWithIndentation),
                 Level:   FormatErrorLevel(ErrBlankLineWithIndentation),
+                Code:    ErrBlankLineWithIndentation,
             })
         }
         return true
@@ -2213,14 +3769,13 @@ func checkTrailingWhitespace(
     errs *[]StyleError,
 ) {
     if loc := reTrailing.FindStringIndex(line); loc != nil {
-        startCol := utf8.RuneCountInString(line[:loc[0]])
-        length := utf8.RuneCountInString(line[loc[0]:loc[1]])
         *errs = append(*errs, StyleError{
             LineNum: lineNum + 1,
-            Start:   startCol,
-            Length:  length,
+            Start:   loc[0],
+            Length:  loc[1] - loc[0],
             Message: FormatMessage(ErrTrailingWhitespace),
             Level:   FormatErrorLevel(ErrTrailingWhitespace),
+            Code:    ErrTrailingWhitespace,
         })
     }
 }
@@ -2241,20 +3796,10 @@ func checkLabelDecl(
                 *errs = append(*errs, StyleError{
                     LineNum: i + 1,
                     Start:   0,
-                    Length:  indent,
+                    Length:  len(leadingWhitespace(line)),
                     Message: FormatMessage(ErrLabelMustHaveNoIndentation),
                     Level:   FormatErrorLevel(ErrLabelMustHaveNoIndentation),
-                })
-            }
-
-            if !snakePattern.MatchString(label) {
-                pos := strings.Index(line, label)
-                *errs = append(*errs, StyleError{
-                    LineNum: i + 1,
-                    Start:   pos,
-                    Length:  len(label),
-                    Message: FormatMessage(ErrLabelMustBeSnakeLowerCase, label),
-                    Level:   FormatErrorLevel(ErrLabelMustBeSnakeLowerCase),
+                    Code:    ErrLabelMustHaveNoIndentation,
                 })
             }
 
@@ -2266,6 +3811,7 @@ func checkLabelDecl(
                     Length:  len(ws) + 1,
                     Message: FormatMessage(ErrColonMustBeAttachedToToken),
                     Level:   FormatErrorLevel(ErrColonMustBeAttachedToToken),
+                    Code:    ErrColonMustBeAttachedToToken,
                 })
             }
 
@@ -2275,48 +3821,6 @@ func checkLabelDecl(
     return false
 }
 
-func checkFuncDeclName(
-    codeOnly,
-    line string,
-    i int,
-    errs *[]StyleError,
-) {
-    if m := reFuncDecl.FindStringSubmatchIndex(codeOnly); m != nil {
-        name := line[m[2]:m[3]]
-        if name != "main" && !reFunctionName.MatchString(name) {
-            *errs = append(*errs, StyleError{
-                LineNum: i + 1,
-                Start:   m[2],
-                Length:  len(name),
-                Message: FormatMessage(ErrFunctionNameMustBeModuleCamelCase, name),
-                Level:   FormatErrorLevel(ErrFunctionNameMustBeModuleCamelCase),
-            })
-        }
-    }
-}
-
-func checkPrevLineOnlyTypeFuncName(lines []string, line string, i int, errs *[]StyleError) {
-    if i == 0 {
-        return
-    }
-    prevTrim := strings.TrimSpace(lines[i-1])
-    if reOnlyType.MatchString(prevTrim) {
-        if m := reSplitFuncName.FindStringSubmatchIndex(line); m != nil {
-            name := line[m[2]:m[3]]
-            if name != "main" && !reFunctionName.MatchString(name) {
-                pos := strings.Index(line, name)
-                *errs = append(*errs, StyleError{
-                    LineNum: i + 1,
-                    Start:   pos,
-                    Length:  len(name),
-                    Message: FormatMessage(ErrLabelMustBeSnakeLowerCase, name),
-                    Level:   FormatErrorLevel(ErrLabelMustBeSnakeLowerCase),
-                })
-            }
-        }
-    }
-}
-
 func checkReturnTypeSameLine(
     lines []string,
     line,
@@ -2332,9 +3836,10 @@ func checkReturnTypeSameLine(
         *errs = append(*errs, StyleError{
             LineNum: i + 1,
             Start:   0,
-            Length:  utf8.RuneCountInString(trim),
+            Length:  len(trim),
             Message: FormatMessage(ErrReturnTypeMustBeOnSameLineAsName),
             Level:   FormatErrorLevel(ErrReturnTypeMustBeOnSameLineAsName),
+            Code:    ErrReturnTypeMustBeOnSameLineAsName,
         })
     }
 }
@@ -2356,6 +3861,7 @@ func checkFuncCallSpace(
                 Length:  m[3] - m[2],
                 Message: FormatMessage(ErrSpaceBeforeFuncCallParen),
                 Level:   FormatErrorLevel(ErrSpaceBeforeFuncCallParen),
+                Code:    ErrSpaceBeforeFuncCallParen,
             })
         }
     }
@@ -2364,18 +3870,33 @@ func checkFuncCallSpace(
 func checkCloseIndent(
     trim,
     codeOnly string,
-    indentStack *[]int,
-) bool {
-    switch {
-    case isPureClose(trim) && len(*indentStack) > 1:
-        *indentStack = (*indentStack)[:len(*indentStack)-1]
-        return true
-    case isInlineClose(trim, codeOnly) && len(*indentStack) > 1:
-        *indentStack = (*indentStack)[:len(*indentStack)-1]
-        return true
-    default:
-        return false
+    indentStack *[]indentFrame,
+) (indentFrame, bool) {
+    n := len(*indentStack)
+    if n > 1 && (isPureClose(trim) || isInlineClose(trim, codeOnly)) {
+        closed := (*indentStack)[n-1]
+        *indentStack = (*indentStack)[:n-1]
+        return closed, true
+    }
+    return (*indentStack)[n-1], false
+}
+
+func isFallThroughMarker(trim string) bool {
+    if idx := strings.Index(trim, "//"); idx >= 0 || strings.Contains(trim, "/*") {
+        if idx < 0 {
+            idx = strings.Index(trim, "/*")
+        }
+        comment := strings.ToLower(trim[idx:])
+        for _, mark := range []string{"fall-through", "fallthrough", "fall through", "falls through"} {
+            if strings.Contains(comment, mark) {
+                return true
+            }
+        }
     }
+    compact := strings.Join(strings.Fields(trim), "")
+    return strings.Contains(compact, "[[fallthrough]]") || strings.Contains(compact, "[[gnu::fallthrough]]") ||
+        strings.Contains(compact, "__attribute__((fallthrough))") ||
+        strings.Contains(compact, "__attribute__((__fallthrough__))")
 }
 
 func checkCaseBlock(
@@ -2384,7 +3905,8 @@ func checkCaseBlock(
     i int,
     lines []string,
     indent int,
-    indentStack *[]int,
+    ind *IndentConfig,
+    indentStack *[]indentFrame,
     caseIndentLevel *int,
     caseEndLine *int,
     errs *[]StyleError,
@@ -2393,6 +3915,23 @@ func checkCaseBlock(
         return false
     }
 
+    step := ind.step()
+    top := (*indentStack)[len(*indentStack)-1]
+    expected := top.Col
+    if !ind.IndentCaseLabels && expected >= step {
+        expected -= step
+    }
+    if indent != expected {
+        *errs = append(*errs, StyleError{
+            LineNum: i + 1,
+            Start:   0,
+            Length:  len(leadingWhitespace(line)),
+            Message: FormatMessage(ErrCaseLabelIndent, expected, describeFrame(top), indent),
+            Level:   FormatErrorLevel(ErrCaseLabelIndent),
+            Code:    ErrCaseLabelIndent,
+        })
+    }
+
     nextIdx := i + 1
     for nextIdx < len(lines) {
         nt := strings.TrimSpace(lines[nextIdx])
@@ -2427,6 +3966,7 @@ func checkCaseBlock(
             Length:  1,
             Message: FormatMessage(WarnCaseBlocksMustNotUseBraces),
             Level:   FormatErrorLevel(WarnCaseBlocksMustNotUseBraces),
+            Code:    WarnCaseBlocksMustNotUseBraces,
         })
         return true
     }
@@ -2439,6 +3979,7 @@ func checkCaseBlock(
             Length:  2,
             Message: FormatMessage(ErrTernaryColonMustHaveSpaceAfter),
             Level:   FormatErrorLevel(ErrTernaryColonMustHaveSpaceAfter),
+            Code:    ErrTernaryColonMustHaveSpaceAfter,
         })
     }
 
@@ -2455,20 +3996,13 @@ func checkCaseBlock(
     }
 
     if found == -1 {
-        hasFallThrough := false
-        for k := i + 1; k < len(lines); k++ {
+        hasFallThrough := isFallThroughMarker(trim)
+        for k := i + 1; k < len(lines) && !hasFallThrough; k++ {
             nextTrim := strings.TrimSpace(lines[k])
             if strings.HasPrefix(nextTrim, "case ") || nextTrim == "default:" {
                 break
             }
-            if (strings.HasPrefix(nextTrim, "//") || strings.HasPrefix(nextTrim, "/*")) &&
-                strings.Contains(nextTrim, "fall-through") {
-                hasFallThrough = true
-                break
-            }
-            if nextTrim != "" && !strings.HasPrefix(nextTrim, "//") && !strings.HasPrefix(nextTrim, "/*") {
-                break
-            }
+            hasFallThrough = isFallThroughMarker(nextTrim)
         }
         if !hasFallThrough {
             *errs = append(*errs, StyleError{
@@ -2477,11 +4011,16 @@ func checkCaseBlock(
                 Length:  1,
                 Message: FormatMessage(WarnCaseBlockMissingBreakOrFallthrough, strings.TrimRight(trim, ":")),
                 Level:   FormatErrorLevel(WarnCaseBlockMissingBreakOrFallthrough),
+                Code:    WarnCaseBlockMissingBreakOrFallthrough,
             })
         }
     } else {
         *caseIndentLevel = indent
-        *indentStack = append(*indentStack, *caseIndentLevel+2)
+        *indentStack = append(*indentStack, indentFrame{
+            Col:   *caseIndentLevel + step,
+            Owner: constructText(trim),
+            Line:  i,
+        })
         *caseEndLine = found
     }
 
@@ -2491,37 +4030,51 @@ func checkCaseBlock(
 func checkIndentRules(
     i int,
     trim, codeOnly string,
-    indent int,
-    indentStack *[]int,
-    nextIndent *int,
+    indent, step int,
+    style StyleMode,
+    closed indentFrame,
+    indentStack *[]indentFrame,
+    nextIndent *indentFrame,
     caseEndLine *int,
     indentForStack *int,
     errs *[]StyleError,
 ) bool {
-    expected := (*indentStack)[len(*indentStack)-1]
-    if *nextIndent >= 0 && !strings.HasPrefix(trim, "{") && trim != "}" && !reInlineBlock.MatchString(trim) {
-        expected = *nextIndent
+    frame := (*indentStack)[len(*indentStack)-1]
+    if nextIndent.Col >= 0 && !strings.HasPrefix(trim, "{") && trim != "}" && !reInlineBlock.MatchString(trim) {
+        frame = *nextIndent
+    }
+    expected := frame.Col
+    fileScope := len(*indentStack) == 1
+    if strings.HasPrefix(trim, "{") && !reInlineBlock.MatchString(trim) {
+        expected += style.braceIndent(step, fileScope)
+    } else if strings.HasPrefix(trim, "}") && closed.Line >= 0 && style.braceIndent(step, fileScope) != 0 {
+        expected = closed.Col - style.bodyIndent(step)
     }
 
-    if *nextIndent >= 0 && (strings.HasPrefix(trim, "{") || reInlineBlock.MatchString(trim)) {
-        *nextIndent = -1
+    if nextIndent.Col >= 0 && (strings.HasPrefix(trim, "{") || reInlineBlock.MatchString(trim)) {
+        nextIndent.Col = -1
     }
 
     *indentForStack = indent
 
     if indent != expected && !isInlineClose(trim, codeOnly) {
+        code, construct := ErrorCode(ErrBlockBodyIndent), describeFrame(frame)
+        if strings.HasPrefix(trim, "}") {
+            code, construct = ErrClosingBraceIndent, describeFrame(closed)
+        }
         *errs = append(*errs, StyleError{
             LineNum: i + 1,
             Start:   0,
-            Length:  indent,
-            Message: FormatMessage(ErrParameterLineWrongIndent, expected, indent),
-            Level:   FormatErrorLevel(ErrParameterLineWrongIndent),
+            Length:  len(leadingWhitespace(codeOnly)),
+            Message: FormatMessage(code, expected, construct, indent),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
         })
         *indentForStack = expected
     }
 
-    if *nextIndent >= 0 && !strings.HasPrefix(trim, "{") {
-        *nextIndent = -1
+    if nextIndent.Col >= 0 && !strings.HasPrefix(trim, "{") {
+        nextIndent.Col = -1
     }
 
     if i == *caseEndLine {
@@ -2529,7 +4082,7 @@ func checkIndentRules(
             *indentStack = (*indentStack)[:len(*indentStack)-1]
         }
         *caseEndLine = -1
-        *nextIndent = -1
+        nextIndent.Col = -1
         return true
     }
 
@@ -2540,10 +4093,14 @@ func checkOpenBrace(
     i int,
     trim string,
     lines []string,
-    indentForStack int,
-    indentStack *[]int,
+    indentForStack, step int,
+    style StyleMode,
+    indentStack *[]indentFrame,
 ) {
     if strings.Contains(trim, "{") && !strings.Contains(trim, "}") && !reInlineBlock.MatchString(trim) {
+        if strings.HasPrefix(trim, "{") {
+            step = style.bodyIndent(step)
+        }
         nextIdx := i + 1
 
         for nextIdx < len(lines) {
@@ -2553,16 +4110,19 @@ func checkOpenBrace(
                 continue
             }
 
-            if reCloseBrace.MatchString(nxt) {
-                *indentStack = append(*indentStack, indentForStack)
-            } else {
-                *indentStack = append(*indentStack, indentForStack+2)
+            frame := blockOwner(lines, i)
+            frame.Col = indentForStack + step
+            if reCloseBrace.MatchString(nxt) && !strings.HasPrefix(trim, "{") {
+                frame.Col = indentForStack
             }
+            *indentStack = append(*indentStack, frame)
             break
         }
 
         if nextIdx >= len(lines) {
-            *indentStack = append(*indentStack, indentForStack+2)
+            frame := blockOwner(lines, i)
+            frame.Col = indentForStack + step
+            *indentStack = append(*indentStack, frame)
         }
     }
 }
@@ -2570,12 +4130,12 @@ func checkOpenBrace(
 func checkControlStmtIndent(
     trim,
     codeOnly string,
-    indentForStack int,
-    nextIndent *int,
+    i, indentForStack, step int,
+    nextIndent *indentFrame,
 ) bool {
     if reControlStmt.MatchString(trim) && !strings.Contains(trim, "{") {
         if !reInlineStmt.MatchString(trim) {
-            *nextIndent = indentForStack + 2
+            *nextIndent = indentFrame{Col: indentForStack + step, Owner: constructText(trim), Line: i}
         }
         return true
     }
@@ -2605,6 +4165,7 @@ func checkInlineBlockOrStmt(
             Length:  1,
             Message: FormatMessage(ErrKeywordMustHaveSpaceBeforeParen),
             Level:   FormatErrorLevel(ErrKeywordMustHaveSpaceBeforeParen),
+            Code:    ErrKeywordMustHaveSpaceBeforeParen,
         })
     }
 
@@ -2617,6 +4178,7 @@ func checkInlineBlockOrStmt(
             Length:  1,
             Message: FormatMessage(ErrKeywordMustHaveSpaceBeforeParen),
             Level:   FormatErrorLevel(ErrKeywordMustHaveSpaceBeforeParen),
+            Code:    ErrKeywordMustHaveSpaceBeforeParen,
         })
     }
 
@@ -2643,6 +4205,7 @@ func checkInlineBlockOrStmt(
             Length:  2,
             Message: FormatMessage(ErrInlineEmptyBraceMustHaveSpaces),
             Level:   FormatErrorLevel(ErrInlineEmptyBraceMustHaveSpaces),
+            Code:    ErrInlineEmptyBraceMustHaveSpaces,
         })
         return true
     }
@@ -2655,6 +4218,7 @@ func checkInlineBlockOrStmt(
                 Length:  1,
                 Message: FormatMessage(ErrExpectedSpaceAfterOpeningBrace),
                 Level:   FormatErrorLevel(ErrExpectedSpaceAfterOpeningBrace),
+                Code:    ErrExpectedSpaceAfterOpeningBrace,
             })
         }
         if inner[len(inner)-1] != ' ' {
@@ -2664,6 +4228,7 @@ func checkInlineBlockOrStmt(
                 Length:  1,
                 Message: FormatMessage(ErrExpectedSpaceAfterClosingBrace),
                 Level:   FormatErrorLevel(ErrExpectedSpaceAfterClosingBrace),
+                Code:    ErrExpectedSpaceAfterClosingBrace,
             })
         }
     }
@@ -2676,6 +4241,7 @@ func checkInlineBlockOrStmt(
                 Length:  1,
                 Message: FormatMessage(ErrInlineBlockMustNotContainNestedBraces),
                 Level:   FormatErrorLevel(ErrInlineBlockMustNotContainNestedBraces),
+                Code:    ErrInlineBlockMustNotContainNestedBraces,
             })
         }
     }
@@ -2695,6 +4261,7 @@ func checkInlineBlockOrStmt(
                 Length:  len(inner),
                 Message: FormatMessage(ErrInlineBlockMustContainOneStatement),
                 Level:   FormatErrorLevel(ErrInlineBlockMustContainOneStatement),
+                Code:    ErrInlineBlockMustContainOneStatement,
             })
         }
     }
@@ -2706,6 +4273,7 @@ func checkInlineBlockOrStmt(
             Length:  m2[1] - m2[0],
             Message: FormatMessage(ErrInlineBlockMustNotContainControlStatements),
             Level:   FormatErrorLevel(ErrInlineBlockMustNotContainControlStatements),
+            Code:    ErrInlineBlockMustNotContainControlStatements,
         })
     }
 
@@ -2780,11 +4348,6 @@ func checkTypeClosing(
     if m := reClosingAll.FindStringSubmatchIndex(codeOnly); m != nil {
         nameStart, nameEnd := m[2], m[3]
 
-        instanceName := ""
-        if nameEnd > nameStart {
-            instanceName = line[nameStart:nameEnd]
-        }
-
         bracePos := strings.Index(lines[i], "}")
         if bracePos >= 0 && bracePos+1 < len(lines[i]) && lines[i][bracePos+1] != ' ' {
             *errs = append(*errs, StyleError{
@@ -2793,59 +4356,19 @@ func checkTypeClosing(
                 Length:  1,
                 Message: FormatMessage(ErrExpectedSpaceAfterClosingBrace),
                 Level:   FormatErrorLevel(ErrExpectedSpaceAfterClosingBrace),
+                Code:    ErrExpectedSpaceAfterClosingBrace,
             })
         }
 
-        if ctx.isTypedef {
-            if instanceName == "" {
-                bracePos := strings.Index(lines[i], "}")
-                *errs = append(*errs, StyleError{
-                    LineNum: i + 1,
-                    Start:   bracePos,
-                    Length:  1,
-                    Message: FormatMessage(WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT, ctx.dataType),
-                    Level:   FormatErrorLevel(WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT),
-                })
-            } else if !strings.HasSuffix(instanceName, "_t") || !snakeTypedefPattern.MatchString(instanceName) {
-                *errs = append(*errs, StyleError{
-                    LineNum: i + 1,
-                    Start:   nameStart,
-                    Length:  nameEnd - nameStart,
-                    Message: FormatMessage(WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT, instanceName),
-                    Level:   FormatErrorLevel(WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT),
-                })
-            }
-        } else if instanceName != "" {
-            if !snakePattern.MatchString(instanceName) {
-                *errs = append(*errs, StyleError{
-                    LineNum: i + 1,
-                    Start:   nameStart,
-                    Length:  nameEnd - nameStart,
-                    Message: FormatMessage(ErrInstanceMustBeSnakeLowerCase, ctx.dataType, instanceName),
-                    Level:   FormatErrorLevel(ErrInstanceMustBeSnakeLowerCase),
-                })
-            }
-            if strings.HasSuffix(instanceName, "_t") {
-                *errs = append(*errs, StyleError{
-                    LineNum: i + 1,
-                    Start:   nameStart,
-                    Length:  nameEnd - nameStart,
-                    Message: FormatMessage(ErrInstanceMustNotEndWithT, ctx.dataType, instanceName),
-                    Level:   FormatErrorLevel(ErrInstanceMustNotEndWithT),
-                })
-            }
-        }
-
-        if ctx.tagName != "" {
-            if !reCamel.MatchString(ctx.tagName) {
-                *errs = append(*errs, StyleError{
-                    LineNum: ctx.tagLine,
-                    Start:   ctx.tagPos,
-                    Length:  len(ctx.tagName),
-                    Message: FormatMessage(ErrTypeTagMustBeCamelCase, ctx.dataType, ctx.tagName),
-                    Level:   FormatErrorLevel(ErrTypeTagMustBeCamelCase),
-                })
-            }
+        if ctx.isTypedef && nameEnd <= nameStart {
+            *errs = append(*errs, StyleError{
+                LineNum: i + 1,
+                Start:   bracePos,
+                Length:  1,
+                Message: FormatMessage(WarnTypedefMissingName, ctx.dataType),
+                Level:   FormatErrorLevel(WarnTypedefMissingName),
+                Code:    WarnTypedefMissingName,
+            })
         }
 
         *typeStack = (*typeStack)[:len(*typeStack)-1]
@@ -2855,50 +4378,6 @@ func checkTypeClosing(
     return false
 }
 
-func checkDataStructureFields(
-    ctx *typeCtx,
-    trim string,
-    line string,
-    codeOnly string,
-    i int,
-    errs *[]StyleError,
-) {
-    if ctx == nil || !ctx.isDataStructure {
-        return
-    }
-
-    switch ctx.dataType {
-    case "enum":
-        if m := reEnumElement.FindStringSubmatchIndex(trim); m != nil {
-            name := trim[m[2]:m[3]]
-            if !screamingSnakePattern.MatchString(name) {
-                start := strings.Index(line, name)
-                *errs = append(*errs, StyleError{
-                    LineNum: i + 1,
-                    Start:   start,
-                    Length:  len(name),
-                    Message: FormatMessage(ErrEnumElementMustBeScreamingSnakeCase, name),
-                    Level:   FormatErrorLevel(ErrEnumElementMustBeScreamingSnakeCase),
-                })
-            }
-        }
-
-    case "struct", "union":
-        if m := reStructFieldName.FindStringSubmatchIndex(codeOnly); m != nil {
-            name := line[m[2]:m[3]]
-            if !snakePattern.MatchString(name) {
-                *errs = append(*errs, StyleError{
-                    LineNum: i + 1,
-                    Start:   m[2],
-                    Length:  m[3] - m[2],
-                    Message: FormatMessage(ErrStructFieldMustBeSnakeLowerCase, ctx.dataType, name),
-                    Level:   FormatErrorLevel(ErrStructFieldMustBeSnakeLowerCase),
-                })
-            }
-        }
-    }
-}
-
 func checkUninitializedDecls(
     ctx *typeCtx,
     codeOnly, line string,
@@ -2914,29 +4393,7 @@ func checkUninitializedDecls(
                 Length:  m[5] - m[4],
                 Message: FormatMessage(WarnDeclaredWithoutInitialization, decl),
                 Level:   FormatErrorLevel(WarnDeclaredWithoutInitialization),
-            })
-        }
-    }
-}
-
-func checkVarNameNotEndWithT(
-    trim, codeOnly, line string,
-    i int,
-    errs *[]StyleError,
-) {
-    if strings.HasPrefix(trim, "typedef") {
-        return
-    }
-    if m := reVarDeclName.FindStringSubmatchIndex(codeOnly); m != nil {
-        nameStart, nameEnd := m[2], m[3]
-        varName := line[nameStart:nameEnd]
-        if strings.HasSuffix(varName, "_t") {
-            *errs = append(*errs, StyleError{
-                LineNum: i + 1,
-                Start:   nameStart,
-                Length:  nameEnd - nameStart,
-                Message: FormatMessage(ErrVariableNameMustNotEndWithT, varName),
-                Level:   FormatErrorLevel(ErrVariableNameMustNotEndWithT),
+                Code:    WarnDeclaredWithoutInitialization,
             })
         }
     }
@@ -2956,73 +4413,11 @@ func checkMultipleVarDecl(
             Length:  1,
             Message: FormatMessage(ErrMultipleVariableDeclarationsNotAllowed),
             Level:   FormatErrorLevel(ErrMultipleVariableDeclarationsNotAllowed),
+            Code:    ErrMultipleVariableDeclarationsNotAllowed,
         })
     }
 }
 
-func checkTypedefFuncPtrName(
-    codeOnly, line string,
-    i int,
-    errs *[]StyleError,
-) {
-    if m := reTypedefFuncPtr.FindStringSubmatchIndex(codeOnly); m != nil {
-        name := line[m[2]:m[3]]
-        if !snakeTypedefPattern.MatchString(name) {
-            *errs = append(*errs, StyleError{
-                LineNum: i + 1,
-                Start:   m[2],
-                Length:  m[3] - m[2],
-                Message: FormatMessage(
-                    WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT,
-                    name,
-                ),
-                Level: FormatErrorLevel(WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT),
-            })
-        }
-    }
-}
-
-func checkTypedefGenericName(
-    codeOnly, line string,
-    i int,
-    errs *[]StyleError,
-) {
-    if m := reTypedefGeneric.FindStringSubmatchIndex(codeOnly); m != nil {
-        name := line[m[2]:m[3]]
-        if !snakeTypedefPattern.MatchString(name) {
-            *errs = append(*errs, StyleError{
-                LineNum: i + 1,
-                Start:   m[2],
-                Length:  m[3] - m[2],
-                Message: FormatMessage(
-                    WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT,
-                    name,
-                ),
-                Level: FormatErrorLevel(WarnTypedefGenericNameMustBeSnakeLowerCaseAndEndWithT),
-            })
-        }
-    }
-}
-
-func checkMacroNameScreamingSnake(
-    codeOnly, line string,
-    i int,
-    errs *[]StyleError,
-) {
-    if m := reDefine.FindStringSubmatchIndex(codeOnly); m != nil {
-        name := line[m[2]:m[3]]
-        if !screamingSnakePattern.MatchString(name) {
-            *errs = append(*errs, StyleError{
-                LineNum: i + 1,
-                Start:   m[2],
-                Length:  m[3] - m[2],
-                Message: FormatMessage(ErrMacroNameMustBeScreamingSnakeCase, name),
-                Level:   FormatErrorLevel(ErrMacroNameMustBeScreamingSnakeCase),
-            })
-        }
-    }
-}
-
 func checkFuncMacroBodyParenthesized(
     line string,
     i int,
@@ -3030,7 +4425,7 @@ func checkFuncMacroBodyParenthesized(
 ) {
     if m := reFuncMacro.FindStringSubmatchIndex(line); m != nil {
         body := line[m[4]:m[5]]
-        if !(strings.HasPrefix(body, "(") && strings.HasSuffix(body, ")")) {
+        if !(strings.HasPrefix(body, "(") && strings.HasSuffix(body, ")")) && !reDoWhileBody.MatchString(body) {
             pos := strings.Index(line, body)
             *errs = append(*errs, StyleError{
                 LineNum: i + 1,
@@ -3038,40 +4433,12 @@ func checkFuncMacroBodyParenthesized(
                 Length:  len(body),
                 Message: FormatMessage(ErrFunctionLikeMacroBodyMustBeParenthesized),
                 Level:   FormatErrorLevel(ErrFunctionLikeMacroBodyMustBeParenthesized),
+                Code:    ErrFunctionLikeMacroBodyMustBeParenthesized,
             })
         }
     }
 }
 
-func checkParamNamesSnakeCase(
-    line, codeOnly string,
-    i int,
-    errs *[]StyleError,
-) {
-    if m := reFuncSignature.FindStringSubmatch(line); m != nil {
-        paramList := m[1]
-        for _, p := range strings.Split(paramList, ",") {
-            p = strings.TrimSpace(p)
-            if p == "" || p == "void" {
-                continue
-            }
-            if mn := reParamName.FindStringSubmatch(p); mn != nil {
-                name := mn[1]
-                idx := strings.Index(line, name)
-                if !snakePattern.MatchString(name) {
-                    *errs = append(*errs, StyleError{
-                        LineNum: i + 1,
-                        Start:   idx,
-                        Length:  len(name),
-                        Message: FormatMessage(ErrParameterNameMustBeSnakeLowerCase, name),
-                        Level:   FormatErrorLevel(ErrParameterNameMustBeSnakeLowerCase),
-                    })
-                }
-            }
-        }
-    }
-}
-
 func checkTernarySpacing(
     codeOnly string,
     i int,
@@ -3084,6 +4451,7 @@ func checkTernarySpacing(
             Length:  1,
             Message: FormatMessage(ErrTernaryQuestionMarkMustHaveSpaceBefore),
             Level:   FormatErrorLevel(ErrTernaryQuestionMarkMustHaveSpaceBefore),
+            Code:    ErrTernaryQuestionMarkMustHaveSpaceBefore,
         })
     }
     for _, loc := range reTernaryQNoSpaceAfter.FindAllStringIndex(codeOnly, -1) {
@@ -3093,6 +4461,7 @@ func checkTernarySpacing(
             Length:  1,
             Message: FormatMessage(ErrTernaryQuestionMarkMustHaveSpaceAfter),
             Level:   FormatErrorLevel(ErrTernaryQuestionMarkMustHaveSpaceAfter),
+            Code:    ErrTernaryQuestionMarkMustHaveSpaceAfter,
         })
     }
     for _, loc := range reTernaryColonNoSpaceBefore.FindAllStringIndex(codeOnly, -1) {
@@ -3102,6 +4471,7 @@ func checkTernarySpacing(
             Length:  1,
             Message: FormatMessage(ErrTernaryColonMustHaveSpaceBefore),
             Level:   FormatErrorLevel(ErrTernaryColonMustHaveSpaceBefore),
+            Code:    ErrTernaryColonMustHaveSpaceBefore,
         })
     }
     for _, loc := range reTernaryColonNoSpaceAfter.FindAllStringIndex(codeOnly, -1) {
@@ -3111,6 +4481,7 @@ func checkTernarySpacing(
             Length:  1,
             Message: FormatMessage(ErrTernaryColonMustHaveSpaceAfter),
             Level:   FormatErrorLevel(ErrTernaryColonMustHaveSpaceAfter),
+            Code:    ErrTernaryColonMustHaveSpaceAfter,
         })
     }
 }
@@ -3128,6 +4499,7 @@ func checkFuncOpeningBraceOwnLine(
             Length:  1,
             Message: FormatMessage(ErrFunctionOpeningBraceMustBeOnOwnLine),
             Level:   FormatErrorLevel(ErrFunctionOpeningBraceMustBeOnOwnLine),
+            Code:    ErrFunctionOpeningBraceMustBeOnOwnLine,
         })
     }
 }
@@ -3138,18 +4510,19 @@ func checkAllmanBrace(
     i int,
     errs *[]StyleError,
 ) {
-    if style != StyleAllman {
+    if !style.controlBraceOwnLine() {
         return
     }
     if reControlStmt.MatchString(codeOnly) && strings.Contains(line, "{") {
         pos := strings.Index(line, "{")
-        kind := reControlStmt.FindString(line)
+        kind := strings.TrimSpace(reControlStmt.FindString(line))
         *errs = append(*errs, StyleError{
             LineNum: i + 1,
             Start:   pos,
             Length:  1,
             Message: FormatMessage(ErrAllmanOpeningBraceMustBeOwnLine, kind),
             Level:   FormatErrorLevel(ErrAllmanOpeningBraceMustBeOwnLine),
+            Code:    ErrAllmanOpeningBraceMustBeOwnLine,
         })
     }
 }
@@ -3160,7 +4533,7 @@ func checkKRBrace(
     i int,
     errs *[]StyleError,
 ) {
-    if style != StyleKR {
+    if style.controlBraceOwnLine() {
         return
     }
 
@@ -3172,6 +4545,7 @@ func checkKRBrace(
                 Length:  1,
                 Message: FormatMessage(ErrKRMissingSpaceBeforeBrace),
                 Level:   FormatErrorLevel(ErrKRMissingSpaceBeforeBrace),
+                Code:    ErrKRMissingSpaceBeforeBrace,
             })
         }
     }
@@ -3185,6 +4559,7 @@ func checkKRBrace(
             Length:  1,
             Message: FormatMessage(ErrKROpeningBraceMustBeSameLineAsControl, kind),
             Level:   FormatErrorLevel(ErrKROpeningBraceMustBeSameLineAsControl),
+            Code:    ErrKROpeningBraceMustBeSameLineAsControl,
         })
     }
 }
@@ -3222,6 +4597,7 @@ func checkClosingBraceOwnLine(
                     Length:  1,
                     Message: FormatMessage(ErrClosingBraceMustBeOwnLine),
                     Level:   FormatErrorLevel(ErrClosingBraceMustBeOwnLine),
+                    Code:    ErrClosingBraceMustBeOwnLine,
                 })
             }
             break
@@ -3250,60 +4626,4871 @@ func checkAllocCallMustBeCast(
             Length:  len(name),
             Message: FormatMessage(ErrAllocCallMustBeCast, name),
             Level:   FormatErrorLevel(ErrAllocCallMustBeCast),
+            Code:    ErrAllocCallMustBeCast,
         })
     }
 }
 
 /** ===============================================================
- *                  C L I  F U N C T I O N S
+ *              C O N F I G  F U N C T I O N S
  * ================================================================ */
-func printContext(lines []string, err StyleError) {
+func defaultConfig() *Config {
+    return &Config{
+        Naming: NamingConfig{
+            Module:        ModuleConfig{Enabled: true, Source: "file"},
+            Function:      NamingRule{Case: "MODULE_camelCase", Exclude: []string{"main"}},
+            StaticFunc:    NamingRule{Case: "MODULE_camelCase"},
+            Global:        NamingRule{Case: "snake_case", ForbidSuffix: "_t"},
+            StaticGlobal:  NamingRule{Case: "snake_case", ForbidSuffix: "_t"},
+            Local:         NamingRule{Case: "snake_case", ForbidSuffix: "_t"},
+            Parameter:     NamingRule{Case: "snake_case"},
+            Macro:         NamingRule{Case: "SCREAMING_SNAKE_CASE"},
+            FunctionMacro: NamingRule{Case: "SCREAMING_SNAKE_CASE"},
+            Typedef:       NamingRule{Case: "snake_case", Suffix: "_t"},
+            StructTag:     NamingRule{Case: "camelCase"},
+            EnumTag:       NamingRule{Case: "camelCase"},
+            Enumerator:    NamingRule{Case: "SCREAMING_SNAKE_CASE"},
+            Field:         NamingRule{Case: "snake_case"},
+            Label:         NamingRule{Case: "snake_case"},
+        },
+        API: APIConfig{
+            Enabled:    true,
+            HeaderDirs: []string{".", "include", "../include"},
+            SourceDirs: []string{".", "src", "../src"},
+        },
+        Header: HeaderConfig{
+            Enabled:         true,
+            RequireExternC:  true,
+            PrivatePatterns: []string{"*_priv.h", "*_private.h", "*_internal.h"},
+        },
+        Doxygen: DoxygenConfig{Enabled: true},
+        Banner: BannerConfig{
+            Template: []string{"SPDX-License-Identifier: {license}", "Copyright (c) {year} {holder}"},
+            Licenses: []string{
+                "MIT", "Apache-2.0", "BSD-2-Clause", "BSD-3-Clause",
+                "GPL-2.0-only", "GPL-2.0-or-later", "GPL-3.0-only", "GPL-3.0-or-later",
+                "LGPL-2.1-only", "LGPL-2.1-or-later", "MPL-2.0",
+            },
+        },
+        Metrics: MetricsConfig{
+            Enabled:       true,
+            MaxComplexity: 15,
+            MaxNesting:    5,
+            MaxStatements: 80,
+            MaxLines:      120,
+            MaxParams:     7,
+        },
+        Indent: IndentConfig{Style: "spaces", Width: 2, TabWidth: 4, IndentCaseLabels: true},
+        Wrap: WrapConfig{
+            Enabled:       true,
+            Continuation:  "align",
+            OperatorBreak: "end",
+            OneArgPerLine: true,
+        },
+        Pointer: PointerConfig{Style: "right"},
+        Std:     "c17",
+        Misra:   MisraConfig{SignificantChars: 31},
+        Secrets: SecretsConfig{
+            Enabled:          true,
+            EntropyThreshold: 4.2,
+            EntropyMinLength: 24,
+        },
+        Switch: SwitchConfig{
+            RequireDefault:          true,
+            DefaultFirstOrLast:      true,
+            NoEmpty:                 true,
+            NoSingleCase:            true,
+            EnumCoverage:            true,
+            EnumCoverageWithDefault: true,
+        },
+        MagicNumbers: MagicNumberConfig{
+            Enabled:          true,
+            Allow:            []string{"0", "1", "-1"},
+            AllowPowersOfTwo: true,
+            AllowShiftCounts: true,
+        },
+        PowerOfTen: PowerOfTenConfig{
+            InitFunctions:    []string{"main", "*init*", "*Init*", "*INIT*"},
+            MaxFunctionLines: 60,
+            MinAssertions:    2,
+            AssertMacros:     []string{"assert", "*_assert", "*ASSERT*", "*Assert*"},
+        },
+        LineLength: LineLengthConfig{
+            Code:           maxLineLength,
+            Comment:        maxLineLength,
+            ExemptURLs:     true,
+            ExemptIncludes: true,
+            ExemptStrings:  true,
+        },
+    }
+}
 
-    start := err.LineNum - 2
+func loadConfig(path string, cfg *Config) (*Config, error) {
+    if path == "" {
+        return cfg, nil
+    }
 
-    if start < 0 {
-        start = 0
+    raw, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
     }
 
-    end := err.LineNum
+    dec := json.NewDecoder(bytes.NewReader(raw))
+    dec.DisallowUnknownFields()
+    if err := dec.Decode(cfg); err != nil {
+        return nil, fmt.Errorf("invalid config %s: %v", path, err)
+    }
 
-    if end >= len(lines) {
-        end = len(lines) - 1
+    if err := cfg.validate(); err != nil {
+        return nil, fmt.Errorf("invalid config %s: %v", path, err)
     }
+    return cfg, nil
+}
 
-    for i := start; i <= end; i++ {
-        line := lines[i]
-        fmt.Printf("%s%3d%s %s|%s ", LineNumCol, i+1, Reset, PipeCol, Reset)
+func (cfg *Config) validate() error {
+    switch cfg.Naming.Module.Source {
+    case "file", "directory":
+    default:
+        return fmt.Errorf("unknown module source %q (use \"file\" or \"directory\")", cfg.Naming.Module.Source)
+    }
+    rules := cfg.Naming.rules()
+    for kind := KindFunction; kind <= KindLabel; kind++ {
+        rule := rules[kind]
+        if rule.Case != "" && rule.Case != "any" && caseStylePatterns[rule.Case] == nil {
+            return fmt.Errorf("unknown case style %q for %s", rule.Case, entityKindNames[kind])
+        }
+        if rule.MaxLength > 0 && rule.MinLength > rule.MaxLength {
+            return fmt.Errorf("min_length greater than max_length for %s", entityKindNames[kind])
+        }
+    }
+    switch cfg.Indent.Style {
+    case "spaces", "tabs", "smart_tabs":
+    default:
+        return fmt.Errorf("unknown indent style %q (use \"spaces\", \"tabs\" or \"smart_tabs\")", cfg.Indent.Style)
+    }
+    if cfg.Indent.Width <= 0 || cfg.Indent.TabWidth <= 0 {
+        return fmt.Errorf("indent width and tab_width must be positive")
+    }
+    if _, err := parseStd(cfg.Std); err != nil {
+        return err
+    }
+    for _, rule := range cfg.Misra.Deviations {
+        if !misraRules[rule] {
+            return fmt.Errorf("unknown MISRA rule %q in misra.deviations", rule)
+        }
+    }
+    if cfg.Misra.SignificantChars <= 0 {
+        return fmt.Errorf("misra significant_chars must be positive")
+    }
+    for _, a := range cfg.MagicNumbers.Allow {
+        if _, ok := numberValue(a); !ok {
+            return fmt.Errorf("invalid number %q in magic_numbers.allow", a)
+        }
+    }
+    builtin := make(map[string]bool)
+    for _, p := range builtinSecretPatterns {
+        builtin[p.name] = true
+    }
+    for _, name := range cfg.Secrets.Disable {
+        if !builtin[name] {
+            return fmt.Errorf("unknown built-in secrets pattern %q in secrets.disable", name)
+        }
+    }
+    if _, _, err := secretPatterns(&cfg.Secrets); err != nil {
+        return err
+    }
+    if cfg.Secrets.EntropyMinLength <= 0 {
+        return fmt.Errorf("secrets entropy_min_length must be positive")
+    }
+    for _, rule := range cfg.Cert.Deviations {
+        if !certRules[rule] {
+            return fmt.Errorf("unknown CERT rule %q in cert.deviations", rule)
+        }
+    }
+    if cfg.PowerOfTen.MaxFunctionLines <= 0 || cfg.PowerOfTen.MinAssertions < 0 {
+        return fmt.Errorf("power_of_ten max_function_lines must be positive and min_assertions not negative")
+    }
+    switch cfg.Pointer.Style {
+    case "left", "right", "middle":
+    default:
+        return fmt.Errorf("unknown pointer style %q (use \"left\", \"right\" or \"middle\")", cfg.Pointer.Style)
+    }
+    if cfg.LineLength.Code <= 0 || cfg.LineLength.Comment <= 0 {
+        return fmt.Errorf("line_length code and comment limits must be positive")
+    }
+    switch cfg.Wrap.Continuation {
+    case "align", "indent":
+    default:
+        return fmt.Errorf("unknown continuation mode %q (use \"align\" or \"indent\")", cfg.Wrap.Continuation)
+    }
+    switch cfg.Wrap.OperatorBreak {
+    case "end", "start", "any":
+    default:
+        return fmt.Errorf("unknown operator_break %q (use \"end\", \"start\" or \"any\")", cfg.Wrap.OperatorBreak)
+    }
+    for _, line := range cfg.Banner.Template {
+        for _, m := range rePlaceholder.FindAllStringSubmatch(line, -1) {
+            switch m[1] {
+            case "year", "filename", "license", "holder":
+            default:
+                return fmt.Errorf("unknown banner placeholder %q", m[0])
+            }
+        }
+    }
+    return nil
+}
 
-        if i == err.LineNum-1 {
-            fmt.Println(highlightError(line, err.Start, err.Length))
-        } else {
-            fmt.Println(highlightLine(line))
+func (c *IndentConfig) step() int {
+    if c.Style == "spaces" {
+        return c.Width
+    }
+    return c.TabWidth
+}
+
+func (c *MisraConfig) applies(rule string) bool {
+    if !c.Enabled {
+        return false
+    }
+    for _, r := range c.Deviations {
+        if r == rule {
+            return false
         }
     }
+    return true
 }
 
-func highlightError(line string, start, length int) string {
+func (n *NamingConfig) rules() map[EntityKind]*NamingRule {
+    return map[EntityKind]*NamingRule{
+        KindFunction:       &n.Function,
+        KindStaticFunction: &n.StaticFunc,
+        KindGlobal:         &n.Global,
+        KindStaticGlobal:   &n.StaticGlobal,
+        KindLocal:          &n.Local,
+        KindParameter:      &n.Parameter,
+        KindMacro:          &n.Macro,
+        KindFunctionMacro:  &n.FunctionMacro,
+        KindTypedef:        &n.Typedef,
+        KindStructTag:      &n.StructTag,
+        KindEnumTag:        &n.EnumTag,
+        KindEnumerator:     &n.Enumerator,
+        KindField:          &n.Field,
+        KindLabel:          &n.Label,
+    }
+}
 
-    r := []rune(line)
-    n := len(r)
+/** ===============================================================
+ *                  S O U R C E  M O D E L
+ * ================================================================ */
+func isIdentStart(c byte) bool {
+    return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
 
-    if start < 0 {
-        start = 0
-    } else if start > n {
-        start = n
+func isIdentChar(c byte) bool {
+    return isIdentStart(c) || isDigitByte(c)
+}
+
+func isDigitByte(c byte) bool {
+    return c >= '0' && c <= '9'
+}
+
+func scanNumber(line string, j int) int {
+    hex := strings.HasPrefix(line[j:], "0x") || strings.HasPrefix(line[j:], "0X")
+    k := j + 1
+    for k < len(line) {
+        ch := line[k]
+        if isIdentChar(ch) || ch == '.' {
+            k++
+            continue
+        }
+        if ch == '+' || ch == '-' {
+            prev := line[k-1]
+            if (!hex && (prev == 'e' || prev == 'E')) || prev == 'p' || prev == 'P' {
+                k++
+                continue
+            }
+        }
+        break
     }
+    return k
+}
 
-    if length < 0 {
-        length = 0
+func scanLine(
+    line string,
+    lineIdx int,
+    inComment *bool,
+    comments *[]cComment,
+) ([]cToken, string) {
+    var toks []cToken
+    code := []byte(line)
+
+    blank := func(from, to int) {
+        for k := from; k < to; k++ {
+            code[k] = ' '
+        }
+    }
+
+    if *inComment && len(*comments) > 0 {
+        last := &(*comments)[len(*comments)-1]
+        last.Text += "\n"
+        last.EndLine = lineIdx
+    }
+
+    j := 0
+    for j < len(line) {
+        if *inComment {
+            stop := len(line)
+            if end := strings.Index(line[j:], "*/"); end >= 0 {
+                stop = j + end + 2
+                *inComment = false
+            }
+            if len(*comments) > 0 {
+                (*comments)[len(*comments)-1].Text += line[j:stop]
+            }
+            blank(j, stop)
+            j = stop
+            continue
+        }
+
+        c := line[j]
+        switch {
+        case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v' || c == '\\':
+            j++
+        case strings.HasPrefix(line[j:], "//"):
+            *comments = append(*comments, cComment{Line: lineIdx, EndLine: lineIdx, Col: j, Text: line[j:]})
+            blank(j, len(line))
+            j = len(line)
+        case strings.HasPrefix(line[j:], "/*"):
+            *comments = append(*comments, cComment{Line: lineIdx, EndLine: lineIdx, Col: j, Text: "/*", Block: true})
+            blank(j, j+2)
+            *inComment = true
+            j += 2
+        case isIdentStart(c):
+            k := j + 1
+            for k < len(line) && isIdentChar(line[k]) {
+                k++
+            }
+            toks = append(toks, cToken{Kind: tokIdent, Text: line[j:k], Line: lineIdx, Col: j})
+            j = k
+        case isDigitByte(c) || (c == '.' && j+1 < len(line) && isDigitByte(line[j+1])):
+            k := scanNumber(line, j)
+            toks = append(toks, cToken{Kind: tokNumber, Text: line[j:k], Line: lineIdx, Col: j})
+            j = k
+        case c == '"' || c == '\'':
+            k := j + 1
+            for k < len(line) && line[k] != c {
+                if line[k] == '\\' {
+                    k++
+                }
+                k++
+            }
+            if k < len(line) {
+                k++
+            } else {
+                k = len(line)
+            }
+            kind := tokString
+            if c == '\'' {
+                kind = tokChar
+            }
+            toks = append(toks, cToken{Kind: kind, Text: line[j:k], Line: lineIdx, Col: j})
+            j = k
+        case c >= utf8.RuneSelf:
+            _, size := utf8.DecodeRuneInString(line[j:])
+            j += size
+        default:
+            text := line[j : j+1]
+            for _, p := range punctuators {
+                if strings.HasPrefix(line[j:], p) {
+                    text = p
+                    break
+                }
+            }
+            toks = append(toks, cToken{Kind: tokPunct, Text: text, Line: lineIdx, Col: j})
+            j += len(text)
+        }
+    }
+
+    return toks, string(code)
+}
+
+func tokenizeSource(lines []string) ([]cToken, []cDirective, []cComment) {
+    var toks []cToken
+    var dirs []cDirective
+    var comments []cComment
+    inComment := false
+
+    for i := 0; i < len(lines); i++ {
+        if !inComment && strings.HasPrefix(strings.TrimSpace(lines[i]), "#") {
+            dir := cDirective{Line: i, EndLine: i}
+            var parts []string
+            for {
+                lineToks, code := scanLine(lines[dir.EndLine], dir.EndLine, &inComment, &comments)
+                dir.Tokens = append(dir.Tokens, lineToks...)
+                code = strings.TrimRight(code, " \t\r")
+                cont := strings.HasSuffix(code, "\\")
+                parts = append(parts, strings.TrimSuffix(code, "\\"))
+                if !cont || dir.EndLine+1 >= len(lines) {
+                    break
+                }
+                dir.EndLine++
+            }
+            dir.Text = strings.TrimSpace(strings.Join(parts, " "))
+            dirs = append(dirs, dir)
+            i = dir.EndLine
+            continue
+        }
+
+        lineToks, _ := scanLine(lines[i], i, &inComment, &comments)
+        toks = append(toks, lineToks...)
+    }
+
+    return toks, dirs, comments
+}
+
+func matchBracket(toks []cToken, i int) int {
+    open := toks[i].Text
+    closeText := map[string]string{"(": ")", "[": "]", "{": "}"}[open]
+    depth := 0
+    for j := i; j < len(toks); j++ {
+        switch toks[j].Text {
+        case open:
+            depth++
+        case closeText:
+            depth--
+            if depth == 0 {
+                return j
+            }
+        }
+    }
+    return len(toks)
+}
+
+func splitTopLevel(toks []cToken, sep string) [][]cToken {
+    var parts [][]cToken
+    depth := 0
+    start := 0
+    for i, t := range toks {
+        switch t.Text {
+        case "(", "[", "{":
+            depth++
+        case ")", "]", "}":
+            depth--
+        case sep:
+            if depth == 0 {
+                parts = append(parts, toks[start:i])
+                start = i + 1
+            }
+        }
+    }
+    return append(parts, toks[start:])
+}
+
+func hasTopLevel(toks []cToken, text string) bool {
+    for _, part := range splitTopLevel(toks, text) {
+        if len(part) != len(toks) {
+            return true
+        }
+    }
+    return false
+}
+
+func hasWord(toks []cToken, words ...string) bool {
+    for _, t := range toks {
+        for _, w := range words {
+            if t.Text == w {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+func typeString(toks []cToken) string {
+    parts := make([]string, 0, len(toks))
+    for _, t := range toks {
+        parts = append(parts, t.Text)
+    }
+    s := strings.Join(parts, " ")
+    for strings.Contains(s, "* *") {
+        s = strings.ReplaceAll(s, "* *", "**")
+    }
+    return s
+}
+
+func parseSource(lines []string) *sourceModel {
+    toks, dirs, comments := tokenizeSource(lines)
+    p := &declParser{
+        model:    &sourceModel{Tokens: toks, Directives: dirs, Comments: comments},
+        typedefs: make(map[string]bool),
+    }
+    p.collectMacros()
+    p.run()
+    return p.model
+}
+
+func (p *declParser) add(kind EntityKind, t cToken, extern bool) {
+    p.model.Idents = append(p.model.Idents, cIdent{
+        Kind:   kind,
+        Name:   t.Text,
+        Line:   t.Line,
+        Col:    t.Col,
+        Extern: extern,
+    })
+}
+
+func (p *declParser) collectMacros() {
+    for _, d := range p.model.Directives {
+        if len(d.Tokens) < 3 || d.Tokens[1].Text != "define" || d.Tokens[2].Kind != tokIdent {
+            continue
+        }
+        name := d.Tokens[2]
+        kind := KindMacro
+        if len(d.Tokens) > 3 && d.Tokens[3].Text == "(" &&
+            d.Tokens[3].Line == name.Line && d.Tokens[3].Col == name.Col+len(name.Text) {
+            kind = KindFunctionMacro
+        }
+        p.add(kind, name, false)
+    }
+}
+
+func (p *declParser) isTypeName(name string) bool {
+    return p.typedefs[name] || wellKnownTypes[name] || strings.HasSuffix(name, "_t")
+}
+
+func (p *declParser) looksLikeDecl(stmt []cToken, fileScope bool) bool {
+    if len(stmt) == 0 || stmt[0].Kind != tokIdent {
+        return false
+    }
+    first := stmt[0].Text
+    switch {
+    case declSpecWords[first] || baseTypeWords[first]:
+        return true
+    case first == "struct" || first == "union" || first == "enum" || first == "__attribute__":
+        return true
+    case keywords[first]:
+        return false
+    case p.isTypeName(first):
+        return true
+    case len(stmt) > 1 && stmt[1].Kind == tokIdent:
+        return true
+    case len(stmt) > 2 && stmt[1].Text == "*" && stmt[2].Kind == tokIdent:
+        if fileScope || len(stmt) == 3 {
+            return true
+        }
+        next := stmt[3].Text
+        return next == "=" || next == "," || next == "["
+    }
+    return false
+}
+
+func (p *declParser) splitSpecifiers(toks []cToken) ([]cToken, []cToken) {
+    sawType := false
+    i := 0
+    for i < len(toks) {
+        t := toks[i]
+        if t.Kind != tokIdent {
+            break
+        }
+        switch {
+        case t.Text == "__attribute__" || t.Text == "__declspec" || t.Text == "_Alignas":
+            if i+1 < len(toks) && toks[i+1].Text == "(" {
+                i = matchBracket(toks, i+1)
+            }
+        case t.Text == "typeof" || t.Text == "typeof_unqual" || t.Text == "__typeof__":
+            sawType = true
+            if i+1 < len(toks) && toks[i+1].Text == "(" {
+                i = matchBracket(toks, i+1)
+            }
+        case declSpecWords[t.Text]:
+        case baseTypeWords[t.Text]:
+            sawType = true
+        case t.Text == "struct" || t.Text == "union" || t.Text == "enum":
+            sawType = true
+            if i+1 < len(toks) && toks[i+1].Kind == tokIdent {
+                i++
+            }
+        case !sawType && !keywords[t.Text]:
+            sawType = true
+        default:
+            return toks[:i], toks[i:]
+        }
+        i++
+    }
+    if i > len(toks) {
+        i = len(toks)
+    }
+    return toks[:i], toks[i:]
+}
+
+func parseDeclarator(toks []cToken) (cDeclarator, bool) {
+    var d cDeclarator
+    i := 0
+    for i < len(toks) && (toks[i].Text == "*" || declSpecWords[toks[i].Text]) {
+        i++
+    }
+    if i >= len(toks) {
+        return d, false
+    }
+
+    if toks[i].Text == "(" {
+        end := matchBracket(toks, i)
+        inner, ok := parseDeclarator(toks[i+1 : end])
+        if !ok {
+            return d, false
+        }
+        inner.isFunc = false
+        return inner, true
+    }
+
+    if toks[i].Kind != tokIdent || keywords[toks[i].Text] {
+        return d, false
+    }
+    d.name = toks[i]
+    d.prefix = toks[:i]
+    if i+1 < len(toks) && toks[i+1].Text == "(" {
+        end := matchBracket(toks, i+1)
+        d.isFunc = true
+        d.params = toks[i+2 : end]
+    }
+    return d, true
+}
+
+func (p *declParser) parseParams(toks []cToken) []cParam {
+    var params []cParam
+    for _, part := range splitTopLevel(toks, ",") {
+        if len(part) == 0 {
+            continue
+        }
+        if len(part) == 1 && (part[0].Text == "void" || part[0].Text == "...") {
+            continue
+        }
+
+        spec, rest := p.splitSpecifiers(part)
+        prm := cParam{Line: part[0].Line, Col: part[0].Col}
+        typeToks := append([]cToken{}, spec...)
+        if d, ok := parseDeclarator(rest); ok {
+            prm.Name = d.name.Text
+            prm.Line = d.name.Line
+            prm.Col = d.name.Col
+            for _, t := range rest {
+                if t != d.name {
+                    typeToks = append(typeToks, t)
+                }
+            }
+        } else {
+            typeToks = append(typeToks, rest...)
+        }
+        prm.Type = typeString(typeToks)
+        params = append(params, prm)
+    }
+    return params
+}
+
+func (p *declParser) makeFunction(spec []cToken, d cDeclarator) cFunction {
+    fn := cFunction{
+        Name:      d.name.Text,
+        Line:      d.name.Line,
+        Col:       d.name.Col,
+        StartLine: d.name.Line,
+        Static:    hasWord(spec, "static"),
+        Inline:    hasWord(spec, "inline", "__inline__"),
+        BodyStart: -1,
+        BodyEnd:   -1,
+    }
+
+    if len(spec) > 0 {
+        fn.StartLine = spec[0].Line
+    }
+
+    var ret []cToken
+    for i := 0; i < len(spec); i++ {
+        switch spec[i].Text {
+        case "static", "extern", "inline", "__inline__", "_Noreturn":
+            continue
+        case "__attribute__", "__declspec", "_Alignas":
+            if i+1 < len(spec) && spec[i+1].Text == "(" {
+                i = matchBracket(spec, i+1)
+            }
+            continue
+        }
+        ret = append(ret, spec[i])
+    }
+    fn.ReturnType = typeString(append(ret, d.prefix...))
+    fn.Params = p.parseParams(d.params)
+    return fn
+}
+
+func (p *declParser) addFunction(fn cFunction) int {
+    kind := KindFunction
+    if fn.Static {
+        kind = KindStaticFunction
+    }
+    p.add(kind, cToken{Text: fn.Name, Line: fn.Line, Col: fn.Col}, false)
+    for _, prm := range fn.Params {
+        if prm.Name != "" {
+            p.add(KindParameter, cToken{Text: prm.Name, Line: prm.Line, Col: prm.Col}, false)
+        }
+    }
+    p.model.Functions = append(p.model.Functions, fn)
+    return len(p.model.Functions) - 1
+}
+
+func aggregateHead(stmt []cToken) (string, *cToken) {
+    n := len(stmt)
+    if n == 0 {
+        return "", nil
+    }
+    isAggregate := func(t cToken) bool {
+        return t.Text == "struct" || t.Text == "union" || t.Text == "enum"
+    }
+    if isAggregate(stmt[n-1]) {
+        return stmt[n-1].Text, nil
+    }
+    if n > 1 && stmt[n-1].Kind == tokIdent && isAggregate(stmt[n-2]) {
+        return stmt[n-2].Text, &stmt[n-1]
+    }
+    return "", nil
+}
+
+func (p *declParser) openScope(
+    parent scopeKind,
+    stmt []cToken,
+    brace int,
+) parseScope {
+    stmt = stripAttributes(stmt)
+    if kw, tag := aggregateHead(stmt); kw != "" {
+        kind, tagKind := scopeStruct, KindStructTag
+        if kw == "enum" {
+            kind, tagKind = scopeEnum, KindEnumTag
+        }
+        if tag != nil {
+            p.add(tagKind, *tag, false)
+        }
+        return parseScope{kind: kind, outer: stmt, funcIdx: -1}
+    }
+
+    if parent == scopeFile || parent == scopeExternC {
+        if len(stmt) == 2 && stmt[0].Text == "extern" && stmt[1].Kind == tokString {
+            return parseScope{kind: scopeExternC, funcIdx: -1}
+        }
+        if p.looksLikeDecl(stmt, true) {
+            spec, rest := p.splitSpecifiers(stmt)
+            if d, ok := parseDeclarator(rest); ok && d.isFunc {
+                fn := p.makeFunction(spec, d)
+                fn.IsDef = true
+                fn.BodyStart = brace
+                return parseScope{kind: scopeFunc, funcIdx: p.addFunction(fn)}
+            }
+        }
+    }
+
+    return parseScope{kind: scopeBlock, funcIdx: -1}
+}
+
+func (p *declParser) declare(scope scopeKind, stmt []cToken) {
+    stmt = stripAttributes(stmt)
+    fileScope := scope == scopeFile || scope == scopeExternC
+    if scope == scopeEnum {
+        if len(stmt) > 0 && stmt[0].Kind == tokIdent {
+            p.add(KindEnumerator, stmt[0], false)
+        }
+        return
+    }
+    if len(stmt) == 0 || (scope != scopeStruct && !p.looksLikeDecl(stmt, fileScope)) {
+        return
+    }
+
+    spec, rest := p.splitSpecifiers(stmt)
+    isTypedef := hasWord(spec, "typedef")
+    isExtern := hasWord(spec, "extern")
+
+    for _, part := range splitTopLevel(rest, ",") {
+        d, ok := parseDeclarator(part)
+        if !ok {
+            continue
+        }
+        switch {
+        case isTypedef:
+            p.typedefs[d.name.Text] = true
+            p.add(KindTypedef, d.name, false)
+        case scope == scopeStruct:
+            p.add(KindField, d.name, false)
+        case d.isFunc:
+            if fileScope {
+                p.addFunction(p.makeFunction(spec, d))
+            }
+        case fileScope && hasWord(spec, "static"):
+            p.add(KindStaticGlobal, d.name, false)
+        case fileScope:
+            p.add(KindGlobal, d.name, isExtern)
+        default:
+            p.add(KindLocal, d.name, false)
+        }
+    }
+}
+
+func (p *declParser) run() {
+    toks := p.model.Tokens
+    scopes := []parseScope{{kind: scopeFile, funcIdx: -1}}
+    var stmt []cToken
+    depth := 0
+    forInit := false
+
+    reset := func() {
+        stmt = nil
+        depth = 0
+        forInit = false
+    }
+
+    for i := 0; i < len(toks); i++ {
+        t := toks[i]
+        cur := scopes[len(scopes)-1].kind
+
+        if t.Kind != tokPunct {
+            stmt = append(stmt, t)
+            continue
+        }
+
+        switch t.Text {
+        case "(", "[":
+            depth++
+            stmt = append(stmt, t)
+        case ")", "]":
+            if depth > 0 {
+                depth--
+            }
+            stmt = append(stmt, t)
+        case "{":
+            if depth > 0 || hasTopLevel(stmt, "=") {
+                i = matchBracket(toks, i)
+                continue
+            }
+            scopes = append(scopes, p.openScope(cur, stmt, i))
+            reset()
+        case "}":
+            p.declare(cur, stmt)
+            reset()
+            if len(scopes) == 1 {
+                continue
+            }
+            closed := scopes[len(scopes)-1]
+            scopes = scopes[:len(scopes)-1]
+            switch closed.kind {
+            case scopeStruct, scopeEnum:
+                stmt = closed.outer
+            case scopeFunc:
+                p.model.Functions[closed.funcIdx].BodyEnd = i
+            }
+        case ";":
+            if depth > 0 {
+                if depth == 1 && !forInit && len(stmt) > 2 && stmt[0].Text == "for" {
+                    p.declare(cur, stmt[2:])
+                    forInit = true
+                }
+                stmt = append(stmt, t)
+                continue
+            }
+            p.declare(cur, stmt)
+            reset()
+        case ",":
+            if depth == 0 && cur == scopeEnum {
+                p.declare(cur, stmt)
+                reset()
+                continue
+            }
+            stmt = append(stmt, t)
+        case ":":
+            if depth == 0 && (cur == scopeFunc || cur == scopeBlock) {
+                if len(stmt) == 1 && stmt[0].Kind == tokIdent && !keywords[stmt[0].Text] {
+                    p.add(KindLabel, stmt[0], false)
+                    reset()
+                    continue
+                }
+                if len(stmt) > 0 && (stmt[0].Text == "case" || stmt[0].Text == "default") {
+                    reset()
+                    continue
+                }
+            }
+            stmt = append(stmt, t)
+        default:
+            stmt = append(stmt, t)
+        }
+    }
+}
+
+/** ===============================================================
+ *                  N A M I N G  E N G I N E
+ * ================================================================ */
+func isHeaderFile(filename string) bool {
+    return strings.HasSuffix(strings.ToLower(filename), ".h")
+}
+
+func moduleName(filename string, mod *ModuleConfig) string {
+    if !mod.Enabled {
+        return ""
+    }
+
+    base := filepath.Base(filename)
+    stem := strings.TrimSuffix(base, filepath.Ext(base))
+    dir := filepath.Base(filepath.Dir(filename))
+    if abs, err := filepath.Abs(filename); err == nil {
+        dir = filepath.Base(filepath.Dir(abs))
+    }
+
+    for _, key := range []string{base, stem, dir} {
+        if prefix, ok := mod.Map[key]; ok {
+            return strings.TrimSuffix(prefix, "_")
+        }
+    }
+
+    name := stem
+    if mod.Source == "directory" {
+        name = dir
+    }
+    return strings.ToUpper(reNonIdentChar.ReplaceAllString(name, "_"))
+}
+
+func modulePrefixFor(module, caseStyle string) string {
+    words := strings.Split(strings.ToLower(module), "_")
+    switch caseStyle {
+    case "snake_case":
+        return strings.ToLower(module) + "_"
+    case "camelCase", "PascalCase":
+        var sb strings.Builder
+        for i, w := range words {
+            if w == "" {
+                continue
+            }
+            if i > 0 || caseStyle == "PascalCase" {
+                w = strings.ToUpper(w[:1]) + w[1:]
+            }
+            sb.WriteString(w)
+        }
+        return sb.String()
+    default:
+        return strings.ToUpper(module) + "_"
+    }
+}
+
+func functionDefinitions(model *sourceModel) map[[2]int]bool {
+    defs := make(map[[2]int]bool)
+    for _, fn := range model.Functions {
+        if fn.IsDef {
+            defs[[2]int{fn.Line, fn.Col}] = true
+        }
+    }
+    return defs
+}
+
+func requiresModulePrefix(kind EntityKind, header, def bool) bool {
+    switch kind {
+    case KindFunction:
+        return header || def
+    case KindMacro, KindFunctionMacro, KindTypedef, KindStructTag, KindEnumTag:
+        return header
+    }
+    return false
+}
+
+func isExcludedName(rule *NamingRule, name string) bool {
+    for _, ex := range rule.Exclude {
+        if ex == name {
+            return true
+        }
+    }
+    return false
+}
+
+func checkNaming(
+    model *sourceModel,
+    filename string,
+    naming *NamingConfig,
+    errs *[]StyleError,
+) {
+    rules := naming.rules()
+    module := moduleName(filename, &naming.Module)
+    header := isHeaderFile(filename)
+    defs := functionDefinitions(model)
+
+    for _, id := range model.Idents {
+        rule := rules[id.Kind]
+        if rule == nil || isExcludedName(rule, id.Name) {
+            continue
+        }
+        checkIdentName(id, rule, errs)
+        if module != "" && requiresModulePrefix(id.Kind, header, defs[[2]int{id.Line, id.Col}]) {
+            checkModulePrefix(id, modulePrefixFor(module, rule.Case), errs)
+        }
+    }
+}
+
+func checkModulePrefix(
+    id cIdent,
+    prefix string,
+    errs *[]StyleError,
+) {
+    if strings.HasPrefix(id.Name, prefix) {
+        return
+    }
+    *errs = append(*errs, StyleError{
+        LineNum: id.Line + 1,
+        Start:   id.Col,
+        Length:  len(id.Name),
+        Message: FormatMessage(ErrNameMissingModulePrefix, entityKindNames[id.Kind], id.Name, prefix),
+        Level:   FormatErrorLevel(ErrNameMissingModulePrefix),
+        Code:    ErrNameMissingModulePrefix,
+    })
+}
+
+func checkIdentName(
+    id cIdent,
+    rule *NamingRule,
+    errs *[]StyleError,
+) {
+    kind := entityKindNames[id.Kind]
+    report := func(code ErrorCode, arg interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: id.Line + 1,
+            Start:   id.Col,
+            Length:  len(id.Name),
+            Message: FormatMessage(code, kind, id.Name, arg),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    core := id.Name
+    if rule.Prefix != "" {
+        if strings.HasPrefix(core, rule.Prefix) {
+            core = strings.TrimPrefix(core, rule.Prefix)
+        } else {
+            report(ErrNameMissingPrefix, rule.Prefix)
+        }
+    }
+    if rule.Suffix != "" {
+        if strings.HasSuffix(core, rule.Suffix) {
+            core = strings.TrimSuffix(core, rule.Suffix)
+        } else {
+            report(ErrNameMissingSuffix, rule.Suffix)
+        }
+    }
+    if rule.ForbidSuffix != "" && strings.HasSuffix(id.Name, rule.ForbidSuffix) {
+        report(ErrNameForbiddenSuffix, rule.ForbidSuffix)
+    }
+    if re := caseStylePatterns[rule.Case]; re != nil && !re.MatchString(core) {
+        report(ErrNameCaseStyle, rule.Case)
+    }
+    if rule.MinLength > 0 && len(id.Name) < rule.MinLength {
+        report(ErrNameTooShort, rule.MinLength)
+    }
+    if rule.MaxLength > 0 && len(id.Name) > rule.MaxLength {
+        report(ErrNameTooLong, rule.MaxLength)
+    }
+}
+
+/** ===============================================================
+ *              A P I  C O N S I S T E N C Y
+ * ================================================================ */
+func findCompanionFile(filename string, dirs []string) string {
+    base := filepath.Base(filename)
+    stem := strings.TrimSuffix(base, filepath.Ext(base))
+    ext := ".h"
+    if isHeaderFile(filename) {
+        ext = ".c"
+    }
+
+    for _, d := range dirs {
+        candidate := filepath.Join(filepath.Dir(filename), d, stem+ext)
+        if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+            return candidate
+        }
+    }
+    return ""
+}
+
+func loadSourceModel(filename string) (*sourceModel, error) {
+    raw, err := os.ReadFile(filename)
+    if err != nil {
+        return nil, err
+    }
+    return parseSource(strings.Split(string(raw), "\n")), nil
+}
+
+func functionsByName(model *sourceModel, defs bool) map[string]cFunction {
+    funcs := make(map[string]cFunction)
+    for _, fn := range model.Functions {
+        if fn.IsDef != defs {
+            continue
+        }
+        if _, ok := funcs[fn.Name]; !ok {
+            funcs[fn.Name] = fn
+        }
+    }
+    return funcs
+}
+
+func checkAPIConsistency(
+    filename string,
+    model *sourceModel,
+    api *APIConfig,
+    errs *[]StyleError,
+) {
+    if !api.Enabled {
+        return
+    }
+
+    dirs := api.HeaderDirs
+    if isHeaderFile(filename) {
+        dirs = api.SourceDirs
+    }
+    companion := findCompanionFile(filename, dirs)
+    if companion == "" {
+        return
+    }
+    other, err := loadSourceModel(companion)
+    if err != nil {
+        return
+    }
+
+    if isHeaderFile(filename) {
+        checkPrototypesDefined(model, other, filepath.Base(companion), errs)
+        return
+    }
+
+    checkOwnHeaderFirst(model, filepath.Base(companion), errs)
+    checkDefinitionsDeclared(model, other, filepath.Base(companion), errs)
+}
+
+func checkOwnHeaderFirst(
+    model *sourceModel,
+    header string,
+    errs *[]StyleError,
+) {
+    for _, d := range model.Directives {
+        if len(d.Tokens) < 3 || d.Tokens[1].Text != "include" {
+            continue
+        }
+        inc := d.Tokens[2]
+        if inc.Kind == tokString && filepath.Base(strings.Trim(inc.Text, `"`)) == header {
+            return
+        }
+        *errs = append(*errs, StyleError{
+            LineNum: d.Line + 1,
+            Start:   d.Tokens[0].Col,
+            Length:  len(d.Text),
+            Message: FormatMessage(ErrOwnHeaderMustBeIncludedFirst, header),
+            Level:   FormatErrorLevel(ErrOwnHeaderMustBeIncludedFirst),
+            Code:    ErrOwnHeaderMustBeIncludedFirst,
+        })
+        return
+    }
+
+    *errs = append(*errs, StyleError{
+        LineNum: 1,
+        Start:   0,
+        Length:  0,
+        Message: FormatMessage(ErrOwnHeaderMustBeIncludedFirst, header),
+        Level:   FormatErrorLevel(ErrOwnHeaderMustBeIncludedFirst),
+        Code:    ErrOwnHeaderMustBeIncludedFirst,
+    })
+}
+
+func checkPrototypesDefined(
+    header *sourceModel,
+    source *sourceModel,
+    sourceName string,
+    errs *[]StyleError,
+) {
+    defs := functionsByName(source, true)
+    for _, proto := range header.Functions {
+        if proto.IsDef || proto.Static {
+            continue
+        }
+        if _, ok := defs[proto.Name]; ok {
+            continue
+        }
+        *errs = append(*errs, StyleError{
+            LineNum: proto.Line + 1,
+            Start:   proto.Col,
+            Length:  len(proto.Name),
+            Message: FormatMessage(ErrPrototypeWithoutDefinition, proto.Name, sourceName),
+            Level:   FormatErrorLevel(ErrPrototypeWithoutDefinition),
+            Code:    ErrPrototypeWithoutDefinition,
+        })
+    }
+}
+
+func checkDefinitionsDeclared(
+    source *sourceModel,
+    header *sourceModel,
+    headerName string,
+    errs *[]StyleError,
+) {
+    protos := functionsByName(header, false)
+    for _, def := range source.Functions {
+        if !def.IsDef || def.Static || def.Name == "main" {
+            continue
+        }
+
+        proto, ok := protos[def.Name]
+        if !ok {
+            *errs = append(*errs, StyleError{
+                LineNum: def.Line + 1,
+                Start:   def.Col,
+                Length:  len(def.Name),
+                Message: FormatMessage(ErrFunctionNotDeclaredInHeader, def.Name, headerName),
+                Level:   FormatErrorLevel(ErrFunctionNotDeclaredInHeader),
+                Code:    ErrFunctionNotDeclaredInHeader,
+            })
+            continue
+        }
+
+        compareSignatures(def, proto, headerName, errs)
+    }
+}
+
+func compareSignatures(
+    def cFunction,
+    proto cFunction,
+    headerName string,
+    errs *[]StyleError,
+) {
+    report := func(line, col, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: line + 1,
+            Start:   col,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    if def.ReturnType != proto.ReturnType {
+        report(def.Line, def.Col, len(def.Name), ErrPrototypeReturnTypeMismatch,
+            def.Name, def.ReturnType, proto.ReturnType, headerName)
+    }
+
+    if len(def.Params) != len(proto.Params) {
+        report(def.Line, def.Col, len(def.Name), ErrPrototypeParamCountMismatch,
+            def.Name, len(def.Params), len(proto.Params), headerName)
+        return
+    }
+
+    for i, dp := range def.Params {
+        pp := proto.Params[i]
+        if pp.Name != "" && dp.Name != pp.Name {
+            report(dp.Line, dp.Col, len(dp.Name), ErrPrototypeParamNameMismatch,
+                i+1, def.Name, dp.Name, pp.Name, headerName)
+        }
+        if dp.Type != pp.Type {
+            report(dp.Line, dp.Col, len(dp.Name), ErrPrototypeParamTypeMismatch,
+                i+1, def.Name, dp.Type, pp.Type, headerName)
+        }
+    }
+}
+
+/** ===============================================================
+ *                 H E A D E R  R U L E S
+ * ================================================================ */
+func isPrivateHeader(filename string, patterns []string) bool {
+    base := filepath.Base(filename)
+    for _, pat := range patterns {
+        if ok, _ := filepath.Match(pat, base); ok {
+            return true
+        }
+    }
+    return false
+}
+
+func findExternCBlock(toks []cToken) (int, int, bool) {
+    for i := 0; i+2 < len(toks); i++ {
+        if toks[i].Text == "extern" && toks[i+1].Text == `"C"` && toks[i+2].Text == "{" {
+            end := matchBracket(toks, i+2)
+            if end >= len(toks) {
+                return toks[i].Line, math.MaxInt32, true
+            }
+            return toks[i].Line, toks[end].Line, true
+        }
+    }
+    return 0, 0, false
+}
+
+func isCplusplusGuard(d cDirective) bool {
+    t := strings.Join(strings.Fields(d.Text), " ")
+    return t == "#ifdef __cplusplus" || strings.HasPrefix(t, "#if defined(__cplusplus)") ||
+        strings.HasPrefix(t, "#if defined __cplusplus")
+}
+
+func cplusplusGuardSpans(dirs []cDirective) [][2]int {
+    var spans [][2]int
+    var open []int
+    for _, d := range dirs {
+        if len(d.Tokens) < 2 {
+            continue
+        }
+        switch d.Tokens[1].Text {
+        case "if", "ifdef", "ifndef":
+            open = append(open, -1)
+            if isCplusplusGuard(d) {
+                open[len(open)-1] = d.Line
+            }
+        case "endif":
+            if len(open) == 0 {
+                continue
+            }
+            if top := open[len(open)-1]; top >= 0 {
+                spans = append(spans, [2]int{top, d.Line})
+            }
+            open = open[:len(open)-1]
+        }
+    }
+    return spans
+}
+
+func checkHeaderRules(
+    filename string,
+    model *sourceModel,
+    hdr *HeaderConfig,
+    errs *[]StyleError,
+) {
+    if !hdr.Enabled || !isHeaderFile(filename) {
+        return
+    }
+
+    report := func(line, col, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: line + 1,
+            Start:   col,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    type publicDecl struct {
+        name      string
+        line, col int
+    }
+    var decls []publicDecl
+
+    for _, fn := range model.Functions {
+        switch {
+        case fn.Static && fn.Inline:
+            continue
+        case fn.Static:
+            report(fn.Line, fn.Col, len(fn.Name), ErrHeaderStaticDeclaration, fn.Name)
+        case fn.IsDef:
+            report(fn.Line, fn.Col, len(fn.Name), ErrHeaderFunctionDefinition, fn.Name)
+        default:
+            decls = append(decls, publicDecl{fn.Name, fn.Line, fn.Col})
+        }
+    }
+
+    for _, id := range model.Idents {
+        switch {
+        case id.Kind == KindStaticGlobal:
+            report(id.Line, id.Col, len(id.Name), ErrHeaderStaticDeclaration, id.Name)
+        case id.Kind == KindGlobal && !id.Extern:
+            report(id.Line, id.Col, len(id.Name), ErrHeaderVariableDefinition, id.Name)
+        case id.Kind == KindGlobal:
+            decls = append(decls, publicDecl{id.Name, id.Line, id.Col})
+        }
+    }
+
+    if !hdr.RequireExternC || len(decls) == 0 || isPrivateHeader(filename, hdr.PrivatePatterns) {
+        return
+    }
+
+    start, end, ok := findExternCBlock(model.Tokens)
+    if !ok {
+        report(decls[0].line, decls[0].col, len(decls[0].name), ErrHeaderMissingExternC)
+        return
+    }
+
+    openGuarded, closeGuarded := false, false
+    for _, sp := range cplusplusGuardSpans(model.Directives) {
+        openGuarded = openGuarded || sp[0] < start && start < sp[1]
+        closeGuarded = closeGuarded || sp[0] < end && end < sp[1]
+    }
+    if !openGuarded || !closeGuarded {
+        report(start, 0, len("extern"), ErrHeaderExternCNotGuarded)
+    }
+
+    for _, d := range decls {
+        if d.line <= start || d.line >= end {
+            report(d.line, d.col, len(d.name), ErrHeaderDeclOutsideExternC, d.name)
+        }
+    }
+}
+
+/** ===============================================================
+ *              D O X Y G E N  D O C U M E N T A T I O N
+ * ================================================================ */
+func parseDocTags(c cComment) []docTag {
+    var tags []docTag
+    for i, text := range strings.Split(c.Text, "\n") {
+        offset := 0
+        if i == 0 {
+            offset = c.Col
+        }
+        for _, m := range reDocTag.FindAllStringSubmatchIndex(text, -1) {
+            tag := docTag{
+                Name: text[m[2]:m[3]],
+                Line: c.Line + i,
+                Col:  offset + m[2] - 1,
+            }
+            if m[4] >= 0 {
+                tag.Arg = text[m[4]:m[5]]
+            }
+            tags = append(tags, tag)
+        }
+    }
+    return tags
+}
+
+func isDocComment(c cComment) bool {
+    return c.Block && strings.HasPrefix(c.Text, "/**") && !strings.HasPrefix(c.Text, "/**/")
+}
+
+func isVoidType(t string) bool {
+    return t == "void"
+}
+
+func describeNames(names []string) string {
+    if len(names) == 0 {
+        return "none"
+    }
+    return strings.Join(names, ", ")
+}
+
+func checkDoxygen(
+    filename string,
+    model *sourceModel,
+    doc *DoxygenConfig,
+    errs *[]StyleError,
+) {
+    if !doc.Enabled || !isHeaderFile(filename) {
+        return
+    }
+
+    known := make(map[string]bool, len(doxygenTags)+len(doc.ExtraTags))
+    for tag := range doxygenTags {
+        known[tag] = true
+    }
+    for _, tag := range doc.ExtraTags {
+        known[tag] = true
+    }
+
+    above := make(map[int]cComment, len(model.Comments))
+    for _, c := range model.Comments {
+        above[c.EndLine+1] = c
+    }
+
+    for _, fn := range model.Functions {
+        report := func(code ErrorCode, args ...interface{}) {
+            *errs = append(*errs, StyleError{
+                LineNum: fn.Line + 1,
+                Start:   fn.Col,
+                Length:  len(fn.Name),
+                Message: FormatMessage(code, args...),
+                Level:   FormatErrorLevel(code),
+                Code:    code,
+            })
+        }
+
+        c, ok := above[fn.StartLine]
+        if !ok || !isDocComment(c) {
+            report(ErrDoxygenMissing, fn.Name)
+            continue
+        }
+
+        var documented []string
+        hasReturn := false
+        for _, tag := range parseDocTags(c) {
+            switch {
+            case tag.Name == "param":
+                documented = append(documented, tag.Arg)
+            case tag.Name == "return" || tag.Name == "returns" || tag.Name == "retval":
+                hasReturn = true
+            case !known[tag.Name]:
+                *errs = append(*errs, StyleError{
+                    LineNum: tag.Line + 1,
+                    Start:   tag.Col,
+                    Length:  len(tag.Name) + 1,
+                    Message: FormatMessage(WarnDoxygenUnknownTag, tag.Name),
+                    Level:   FormatErrorLevel(WarnDoxygenUnknownTag),
+                    Code:    WarnDoxygenUnknownTag,
+                })
+            }
+        }
+
+        var actual []string
+        for _, prm := range fn.Params {
+            if prm.Name != "" {
+                actual = append(actual, prm.Name)
+            }
+        }
+        if strings.Join(documented, ",") != strings.Join(actual, ",") {
+            report(ErrDoxygenParamMismatch, fn.Name, describeNames(documented), describeNames(actual))
+        }
+
+        void := isVoidType(fn.ReturnType)
+        if !void && !hasReturn {
+            report(ErrDoxygenMissingReturn, fn.Name, fn.ReturnType)
+        }
+        if void && hasReturn {
+            report(ErrDoxygenUnexpectedReturn, fn.Name)
+        }
+    }
+}
+
+/** ===============================================================
+ *                  F I L E  B A N N E R
+ * ================================================================ */
+func stripCommentDecoration(line string) string {
+    t := strings.TrimSpace(line)
+    t = strings.TrimSuffix(t, "*/")
+    switch {
+    case strings.HasPrefix(t, "//"):
+        t = strings.TrimLeft(t, "/")
+    case strings.HasPrefix(t, "/*"):
+        t = strings.TrimLeft(t[2:], "*")
+    default:
+        t = strings.TrimLeft(t, "*")
+    }
+    return strings.TrimSpace(t)
+}
+
+func findBanner(lines []string, comments []cComment) (fileBanner, bool) {
+    first := 0
+    for first < len(lines) && strings.TrimSpace(lines[first]) == "" {
+        first++
+    }
+    if len(comments) == 0 || comments[0].Line != first {
+        return fileBanner{Start: first, End: first - 1, Copyright: -1, SPDX: -1}, false
+    }
+
+    b := fileBanner{Start: first, End: comments[0].EndLine, Copyright: -1, SPDX: -1}
+    prev := comments[0]
+    for _, c := range comments[1:] {
+        if prev.Block || c.Block || c.Line != prev.EndLine+1 || c.Col != prev.Col {
+            break
+        }
+        b.End = c.EndLine
+        prev = c
+    }
+    for i := b.Start; i <= b.End; i++ {
+        b.Text = append(b.Text, stripCommentDecoration(lines[i]))
+    }
+
+    for i, t := range b.Text {
+        if m := reCopyright.FindStringSubmatch(t); m != nil && b.Copyright < 0 {
+            b.Copyright = b.Start + i
+            b.Years = m[1]
+            b.Holder = strings.TrimSpace(m[4])
+            b.FirstYear, _ = strconv.Atoi(m[2])
+            b.LastYear = b.FirstYear
+            if m[3] != "" {
+                b.LastYear, _ = strconv.Atoi(m[3])
+            }
+        }
+        if m := reSPDX.FindStringSubmatch(t); m != nil && b.SPDX < 0 {
+            b.SPDX = b.Start + i
+            b.License = strings.TrimSpace(m[1])
+        }
+    }
+    return b, true
+}
+
+func licenseIDs(expr string) []string {
+    var ids []string
+    for _, f := range strings.FieldsFunc(expr, func(r rune) bool {
+        return r == ' ' || r == '(' || r == ')'
+    }) {
+        switch f {
+        case "AND", "OR", "WITH", "and", "or", "with":
+            continue
+        }
+        ids = append(ids, f)
+    }
+    return ids
+}
+
+func templatePattern(line, filename string, cfg *BannerConfig) *regexp.Regexp {
+    holder := `.+`
+    if cfg.Holder != "" {
+        holder = regexp.QuoteMeta(cfg.Holder)
+    }
+    values := map[string]string{
+        "year":     `\d{4}(?:\s*-\s*\d{4})?`,
+        "filename": regexp.QuoteMeta(filepath.Base(filename)),
+        "license":  `[A-Za-z0-9.+\-() ]+`,
+        "holder":   holder,
+    }
+
+    var sb strings.Builder
+    sb.WriteString(`^`)
+    last := 0
+    for _, m := range rePlaceholder.FindAllStringSubmatchIndex(line, -1) {
+        name := line[m[2]:m[3]]
+        if name == "holder" && cfg.Holder == "" {
+            sb.WriteString(regexp.QuoteMeta(strings.TrimRight(line[last:m[0]], " ")))
+            sb.WriteString(`(?:\s+.+)?`)
+        } else {
+            sb.WriteString(regexp.QuoteMeta(line[last:m[0]]))
+            sb.WriteString(values[name])
+        }
+        last = m[1]
+    }
+    sb.WriteString(regexp.QuoteMeta(line[last:]))
+    sb.WriteString(`$`)
+    return regexp.MustCompile(sb.String())
+}
+
+func checkBanner(
+    filename string,
+    lines []string,
+    model *sourceModel,
+    cfg *BannerConfig,
+    errs *[]StyleError,
+) {
+    if !cfg.Enabled {
+        return
+    }
+
+    report := func(line, col, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: line + 1,
+            Start:   col,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    b, ok := findBanner(lines, model.Comments)
+    if !ok {
+        report(0, 0, 1, ErrBannerMissing)
+        return
+    }
+
+    valid := true
+    if b.Copyright < 0 {
+        report(b.Start, 0, len(lines[b.Start]), ErrBannerMissingCopyright)
+        valid = false
+    } else if year := time.Now().Year(); b.FirstYear < 1970 || b.LastYear < b.FirstYear || b.LastYear > year {
+        col := strings.Index(lines[b.Copyright], b.Years)
+        report(b.Copyright, col, len(b.Years), ErrBannerInvalidYear, b.Years)
+        valid = false
+    }
+
+    if b.SPDX < 0 {
+        report(b.Start, 0, len(lines[b.Start]), ErrBannerMissingSPDX)
+        valid = false
+    } else if len(cfg.Licenses) > 0 {
+        col := strings.Index(lines[b.SPDX], b.License)
+        for _, id := range licenseIDs(b.License) {
+            allowed := false
+            for _, l := range cfg.Licenses {
+                if id == l {
+                    allowed = true
+                    break
+                }
+            }
+            if !allowed {
+                report(b.SPDX, col, len(b.License), ErrBannerLicenseNotAllowed, id, strings.Join(cfg.Licenses, ", "))
+                valid = false
+            }
+        }
+    }
+
+    if !valid {
+        return
+    }
+
+    next := 0
+    for _, tl := range cfg.Template {
+        if strings.TrimSpace(tl) == "" {
+            continue
+        }
+        re := templatePattern(strings.TrimSpace(tl), filename, cfg)
+        found := false
+        for next < len(b.Text) {
+            next++
+            if re.MatchString(b.Text[next-1]) {
+                found = true
+                break
+            }
+        }
+        if !found {
+            report(b.Start, 0, len(lines[b.Start]), ErrBannerTemplateMismatch, strings.TrimSpace(tl))
+            return
+        }
+    }
+}
+
+func renderBanner(filename string, b fileBanner, cfg *BannerConfig) []string {
+    year := strconv.Itoa(time.Now().Year())
+    if b.Copyright >= 0 && b.FirstYear >= 1970 && b.FirstYear < time.Now().Year() {
+        year = strconv.Itoa(b.FirstYear) + "-" + year
+    }
+    license := b.License
+    if len(cfg.Licenses) > 0 {
+        ok := license != ""
+        for _, id := range licenseIDs(license) {
+            found := false
+            for _, l := range cfg.Licenses {
+                found = found || id == l
+            }
+            ok = ok && found
+        }
+        if !ok {
+            license = cfg.Licenses[0]
+        }
+    }
+    holder := cfg.Holder
+    if holder == "" {
+        holder = b.Holder
+    }
+    values := map[string]string{
+        "year":     year,
+        "filename": filepath.Base(filename),
+        "license":  license,
+        "holder":   holder,
+    }
+
+    out := []string{"/*"}
+    for _, tl := range cfg.Template {
+        if holder == "" {
+            tl = strings.ReplaceAll(tl, " {holder}", "")
+        }
+        text := rePlaceholder.ReplaceAllStringFunc(tl, func(p string) string {
+            return values[p[1:len(p)-1]]
+        })
+        out = append(out, strings.TrimRight(" * "+strings.TrimSpace(text), " "))
+    }
+    return append(out, " */")
+}
+
+func fixBanner(filename string, lines []string, cfg *BannerConfig) ([]string, bool) {
+    if !cfg.Enabled {
+        return lines, false
+    }
+
+    _, _, comments := tokenizeSource(lines)
+    var errs []StyleError
+    checkBanner(filename, lines, &sourceModel{Comments: comments}, cfg, &errs)
+    if len(errs) == 0 {
+        return lines, false
+    }
+
+    b, ok := findBanner(lines, comments)
+    replace := ok && (b.Copyright >= 0 || b.SPDX >= 0)
+    if !replace {
+        b = fileBanner{Copyright: -1, SPDX: -1}
+    }
+
+    out := renderBanner(filename, b, cfg)
+    if replace {
+        out = append(out, lines[b.End+1:]...)
+        out = append(lines[:b.Start:b.Start], out...)
+    } else {
+        out = append(out, "")
+        out = append(out, lines...)
+    }
+    return out, true
+}
+
+func fixFile(filename string, cfg *Config) (bool, error) {
+    info, err := os.Stat(filename)
+    if err != nil {
+        return false, err
+    }
+    raw, err := os.ReadFile(filename)
+    if err != nil {
+        return false, err
+    }
+
+    lines, changed := fixBanner(filename, strings.Split(string(raw), "\n"), &cfg.Banner)
+    if !changed {
+        return false, nil
+    }
+    return true, os.WriteFile(filename, []byte(strings.Join(lines, "\n")), info.Mode())
+}
+
+/** ===============================================================
+ *              F U N C T I O N  M E T R I C S
+ * ================================================================ */
+func computeMetrics(filename string, model *sourceModel, fn cFunction) funcMetrics {
+    m := funcMetrics{
+        File:       filename,
+        Function:   fn.Name,
+        Line:       fn.Line + 1,
+        Complexity: 1,
+        Params:     len(fn.Params),
+    }
+    toks := model.Tokens
+    m.Lines = toks[fn.BodyEnd].Line - fn.StartLine + 1
+
+    depth, parens := 0, 0
+    for i := fn.BodyStart + 1; i < fn.BodyEnd; i++ {
+        t := toks[i]
+        if t.Kind == tokString || t.Kind == tokChar {
+            continue
+        }
+        switch t.Text {
+        case "{":
+            depth++
+            if depth > m.Nesting {
+                m.Nesting = depth
+            }
+        case "}":
+            depth--
+        case "(":
+            parens++
+        case ")":
+            parens--
+        case ";":
+            if parens == 0 {
+                m.Statements++
+            }
+        case "if", "for", "case", "&&", "||", "?":
+            m.Complexity++
+            if t.Text == "if" || t.Text == "for" {
+                m.Statements++
+            }
+        case "while":
+            m.Complexity++
+            if i+1 < fn.BodyEnd && toks[i+1].Text == "(" {
+                if end := matchBracket(toks, i+1); end+1 < fn.BodyEnd && toks[end+1].Text == ";" {
+                    continue
+                }
+            }
+            m.Statements++
+        case "switch", "do":
+            m.Statements++
+        case "return":
+            m.Returns++
+        }
+    }
+    return m
+}
+
+func functionMetrics(filename string, model *sourceModel) []funcMetrics {
+    var out []funcMetrics
+    for _, fn := range model.Functions {
+        if fn.IsDef && fn.BodyStart >= 0 && fn.BodyEnd > fn.BodyStart {
+            out = append(out, computeMetrics(filename, model, fn))
+        }
+    }
+    return out
+}
+
+func checkMetrics(
+    filename string,
+    model *sourceModel,
+    cfg *MetricsConfig,
+    errs *[]StyleError,
+) {
+    if !cfg.Enabled {
+        return
+    }
+
+    functions := make(map[string]cFunction, len(model.Functions))
+    for _, fn := range model.Functions {
+        if fn.IsDef {
+            functions[fn.Name] = fn
+        }
+    }
+
+    for _, m := range functionMetrics(filename, model) {
+        fn := functions[m.Function]
+        limits := []struct {
+            code  ErrorCode
+            value int
+            max   int
+        }{
+            {WarnFunctionTooComplex, m.Complexity, cfg.MaxComplexity},
+            {WarnFunctionTooDeep, m.Nesting, cfg.MaxNesting},
+            {WarnFunctionTooManyStatements, m.Statements, cfg.MaxStatements},
+            {WarnFunctionTooLong, m.Lines, cfg.MaxLines},
+            {WarnFunctionTooManyParams, m.Params, cfg.MaxParams},
+            {WarnFunctionTooManyReturns, m.Returns, cfg.MaxReturns},
+        }
+        for _, l := range limits {
+            if l.max > 0 && l.value > l.max {
+                *errs = append(*errs, StyleError{
+                    LineNum: fn.Line + 1,
+                    Start:   fn.Col,
+                    Length:  len(fn.Name),
+                    Message: FormatMessage(l.code, fn.Name, l.value, l.max),
+                    Level:   FormatErrorLevel(l.code),
+                    Code:    l.code,
+                })
+            }
+        }
+    }
+}
+
+func writeMetricsReport(w io.Writer, format string, metrics []funcMetrics) error {
+    switch format {
+    case "json":
+        if metrics == nil {
+            metrics = []funcMetrics{}
+        }
+        enc := json.NewEncoder(w)
+        enc.SetIndent("", "  ")
+        return enc.Encode(metrics)
+    case "csv":
+        cw := csv.NewWriter(w)
+        cw.Write([]string{"file", "function", "line", "complexity", "max_nesting", "statements", "lines", "params", "returns"})
+        for _, m := range metrics {
+            cw.Write([]string{
+                m.File, m.Function, strconv.Itoa(m.Line),
+                strconv.Itoa(m.Complexity), strconv.Itoa(m.Nesting), strconv.Itoa(m.Statements),
+                strconv.Itoa(m.Lines), strconv.Itoa(m.Params), strconv.Itoa(m.Returns),
+            })
+        }
+        cw.Flush()
+        return cw.Error()
+    }
+    return fmt.Errorf("unknown metrics format %q (use \"json\" or \"csv\")", format)
+}
+
+func metricsReport(format string, files []string) error {
+    var all []funcMetrics
+    for _, f := range files {
+        raw, err := os.ReadFile(f)
+        if err != nil {
+            return err
+        }
+        model := parseSource(strings.Split(string(raw), "\n"))
+        all = append(all, functionMetrics(f, model)...)
+    }
+    return writeMetricsReport(os.Stdout, format, all)
+}
+
+/** ===============================================================
+ *                  S U M M A R Y  R E P O R T
+ * ================================================================ */
+func countLines(lines []string) (code, comment, blank int) {
+    toks, dirs, comments := tokenizeSource(lines)
+    hasCode := make([]bool, len(lines))
+    hasComment := make([]bool, len(lines))
+    for _, t := range toks {
+        hasCode[t.Line] = true
+    }
+    for _, d := range dirs {
+        for l := d.Line; l <= d.EndLine && l < len(lines); l++ {
+            hasCode[l] = true
+        }
+    }
+    for _, c := range comments {
+        for l := c.Line; l <= c.EndLine && l < len(lines); l++ {
+            hasComment[l] = true
+        }
+    }
+
+    n := len(lines)
+    if n > 0 && lines[n-1] == "" {
+        n--
+    }
+    for i := 0; i < n; i++ {
+        switch {
+        case strings.TrimSpace(lines[i]) == "":
+            blank++
+        case hasCode[i] || !hasComment[i]:
+            code++
+        default:
+            comment++
+        }
+    }
+    return code, comment, blank
+}
+
+func perKLOC(findings, code int) float64 {
+    if code == 0 {
+        return 0
+    }
+    return float64(findings) * 1000 / float64(code)
+}
+
+func summarizeFile(filename string, lines []string, errs []StyleError) fileSummary {
+    s := fileSummary{File: filename, Rules: make(map[ErrorCode]int)}
+    s.Code, s.Comment, s.Blank = countLines(lines)
+    for _, e := range errs {
+        switch e.Level {
+        case LevelError:
+            s.Errors++
+        case LevelWarning:
+            s.Warnings++
+        }
+        s.Rules[e.Code]++
+    }
+    return s
+}
+
+func ruleTemplate(code ErrorCode) string {
+    msg := strings.NewReplacer("%s", "…", "%d", "N", "%q", "…", "\n", " ").Replace(errorInfos[code].Message)
+    if r := []rune(msg); len(r) > 70 {
+        msg = string(r[:67]) + "..."
+    }
+    return msg
+}
+
+func printSummary(files []fileSummary) {
+    type tally struct {
+        key   string
+        count int
+        code  int
+    }
+    sortTally := func(m map[string]*tally) []*tally {
+        out := make([]*tally, 0, len(m))
+        for _, t := range m {
+            out = append(out, t)
+        }
+        sort.Slice(out, func(i, j int) bool {
+            if out[i].count != out[j].count {
+                return out[i].count > out[j].count
+            }
+            return out[i].key < out[j].key
+        })
+        return out
+    }
+
+    rules := make(map[string]*tally)
+    dirs := make(map[string]*tally)
+    totalErrors, totalWarnings, totalCode := 0, 0, 0
+    for _, f := range files {
+        totalErrors += f.Errors
+        totalWarnings += f.Warnings
+        totalCode += f.Code
+
+        dir := filepath.Dir(f.File)
+        if dirs[dir] == nil {
+            dirs[dir] = &tally{key: dir}
+        }
+        dirs[dir].count += f.Errors + f.Warnings
+        dirs[dir].code += f.Code
+
+        for code, n := range f.Rules {
+            key := fmt.Sprintf("[%s] %s", errorInfos[code].Level, ruleTemplate(code))
+            if rules[key] == nil {
+                rules[key] = &tally{key: key}
+            }
+            rules[key].count += n
+        }
+    }
+
+    sep := func() {
+        fmt.Printf("%s------------------------------------------------------------------%s\n", LineNumCol, Reset)
+    }
+
+    fmt.Printf("\n")
+    sep()
+    fmt.Printf("%sSummary: %d file(s), %s%d error(s)%s & %s%d warning(s)%s, %d lines of code, %.1f findings/KLOC\n",
+        TitleCol, len(files),
+        ErrorFg, totalErrors, Reset,
+        WarningFg, totalWarnings, Reset,
+        totalCode, perKLOC(totalErrors+totalWarnings, totalCode),
+    )
+
+    sep()
+    fmt.Printf("%sFindings per rule%s\n", TitleCol, Reset)
+    for _, t := range sortTally(rules) {
+        fmt.Printf("%7d  %s\n", t.count, t.key)
+    }
+
+    sep()
+    fmt.Printf("%sFindings per directory%s\n", TitleCol, Reset)
+    for _, t := range sortTally(dirs) {
+        fmt.Printf("%7d  %8.1f/KLOC  %s\n", t.count, perKLOC(t.count, t.code), t.key)
+    }
+
+    sorted := append([]fileSummary{}, files...)
+    sort.SliceStable(sorted, func(i, j int) bool {
+        return sorted[i].Errors+sorted[i].Warnings > sorted[j].Errors+sorted[j].Warnings
+    })
+
+    sep()
+    fmt.Printf("%sTop offending files%s\n", TitleCol, Reset)
+    for i, f := range sorted {
+        if i == summaryTopFiles || f.Errors+f.Warnings == 0 {
+            break
+        }
+        fmt.Printf("%7d  %8.1f/KLOC  %s\n", f.Errors+f.Warnings, perKLOC(f.Errors+f.Warnings, f.Code), f.File)
+    }
+
+    sep()
+    fmt.Printf("%sLines per file%s\n", TitleCol, Reset)
+    fmt.Printf("%7s %7s %7s %7s %7s  %s\n", "code", "comment", "blank", "errors", "warns", "file")
+    for _, f := range files {
+        fmt.Printf("%7d %7d %7d %7d %7d  %s\n", f.Code, f.Comment, f.Blank, f.Errors, f.Warnings, f.File)
+    }
+    sep()
+    fmt.Printf("\n")
+}
+
+/** ===============================================================
+ *                  W R A P P I N G  R U L E S
+ * ================================================================ */
+func isBinaryOperatorAt(toks []cToken, i int) bool {
+    if i <= 0 || i >= len(toks) || !binaryOperators[toks[i].Text] {
+        return false
+    }
+    prev := toks[i-1]
+    switch prev.Kind {
+    case tokIdent:
+        return !keywords[prev.Text]
+    case tokNumber, tokString, tokChar:
+        return true
+    }
+    return prev.Text == ")" || prev.Text == "]"
+}
+
+func isCallGroup(toks []cToken, open int, declared map[[2]int]bool, inBody []bool) bool {
+    if open == 0 || toks[open].Text != "(" {
+        return false
+    }
+    prev := toks[open-1]
+    if prev.Kind != tokIdent || keywords[prev.Text] || declared[[2]int{prev.Line, prev.Col}] {
+        return false
+    }
+    return inBody[open] || !isTypeTokenAt(toks, open-2, nil)
+}
+
+func scanWrapping(model *sourceModel) ([]wrapBreak, []wrapCall) {
+    toks := model.Tokens
+    inBody := make([]bool, len(toks))
+    declared := make(map[[2]int]bool, len(model.Functions))
+    for _, fn := range model.Functions {
+        declared[[2]int{fn.Line, fn.Col}] = true
+        if fn.BodyStart >= 0 && fn.BodyEnd > fn.BodyStart {
+            for i := fn.BodyStart + 1; i < fn.BodyEnd && i < len(toks); i++ {
+                inBody[i] = true
+            }
+        }
+    }
+
+    type group struct {
+        open    int
+        brace   bool
+        init    bool
+        wrapped bool
+        commas  []int
+    }
+    var stack []group
+    var breaks []wrapBreak
+    var calls []wrapCall
+    stmtStart := 0
+
+    for i, t := range toks {
+        if i > 0 && t.Line > toks[i-1].Line {
+            var top *group
+            if len(stack) > 0 {
+                top = &stack[len(stack)-1]
+                top.wrapped = true
+            }
+            switch {
+            case top != nil && !top.brace:
+                breaks = append(breaks, wrapBreak{Tok: i, Open: top.open, StmtStart: stmtStart})
+            case top != nil && top.init:
+            case inBody[i] && stmtStart < i:
+                breaks = append(breaks, wrapBreak{Tok: i, Open: -1, StmtStart: stmtStart})
+            }
+        }
+
+        switch t.Text {
+        case "(", "[":
+            stack = append(stack, group{open: i})
+        case "{":
+            init := false
+            if i > 0 {
+                prev := toks[i-1].Text
+                init = prev == "="
+                if n := len(stack); n > 0 {
+                    init = init || !stack[n-1].brace ||
+                        (stack[n-1].init && (prev == "," || prev == "{"))
+                }
+            }
+            stack = append(stack, group{open: i, brace: true, init: init})
+            stmtStart = i + 1
+        case ")", "]", "}":
+            if n := len(stack); n > 0 {
+                g := stack[n-1]
+                stack = stack[:n-1]
+                if t.Text == ")" && g.wrapped && isCallGroup(toks, g.open, declared, inBody) {
+                    calls = append(calls, wrapCall{Open: g.open, Close: i, Commas: g.commas})
+                }
+                if t.Text == ")" && g.open > 0 && hasWord(toks[g.open-1:g.open], "if", "for", "while", "switch") {
+                    stmtStart = i + 1
+                }
+            }
+            if t.Text == "}" {
+                stmtStart = i + 1
+            }
+        case ",":
+            if n := len(stack); n > 0 && !stack[n-1].brace {
+                stack[n-1].commas = append(stack[n-1].commas, i)
+            }
+        case ";":
+            if n := len(stack); n == 0 || stack[n-1].brace {
+                stmtStart = i + 1
+            }
+        case "else", "do":
+            stmtStart = i + 1
+        case ":":
+            if n := len(stack); n == 0 || stack[n-1].brace {
+                if s := toks[stmtStart].Text; s == "case" || s == "default" || stmtStart == i-1 {
+                    stmtStart = i + 1
+                }
+            }
+        }
+    }
+    return breaks, calls
+}
+
+func continuationLines(model *sourceModel) map[int]bool {
+    breaks, _ := scanWrapping(model)
+    lines := make(map[int]bool, len(breaks))
+    for _, b := range breaks {
+        lines[model.Tokens[b.Tok].Line] = true
+    }
+    return lines
+}
+
+func tokenColumn(lines []string, t cToken, tabWidth int) int {
+    line := lines[t.Line]
+    if t.Col > len(line) {
+        return t.Col
+    }
+    return displayColumn(line, t.Col, tabWidth) - 1
+}
+
+func checkWrapping(
+    lines []string,
+    model *sourceModel,
+    cfg *Config,
+    errs *[]StyleError,
+) {
+    wc := &cfg.Wrap
+    if !wc.Enabled {
+        return
+    }
+    tabWidth := cfg.Indent.TabWidth
+    width := wc.ContinuationIndent
+    if width <= 0 {
+        width = cfg.Indent.step()
+    }
+
+    toks := model.Tokens
+    report := func(t cToken, start, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: t.Line + 1,
+            Start:   start,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    breaks, calls := scanWrapping(model)
+    for _, b := range breaks {
+        t, prev := toks[b.Tok], toks[b.Tok-1]
+
+        switch {
+        case wc.OperatorBreak == "end" && isBinaryOperatorAt(toks, b.Tok):
+            report(t, t.Col, len(t.Text), ErrOperatorAtLineStart, t.Text)
+        case wc.OperatorBreak == "start" && isBinaryOperatorAt(toks, b.Tok-1):
+            report(prev, prev.Col, len(prev.Text), ErrOperatorAtLineEnd, prev.Text)
+        }
+
+        if t.Text == ")" || t.Text == "]" {
+            continue
+        }
+
+        var expected int
+        var construct string
+        if b.Open >= 0 {
+            open := toks[b.Open]
+            openLine := lines[open.Line]
+            construct = fmt.Sprintf("'%s' (line %d)", constructText(strings.TrimSpace(openLine[:open.Col+1])), open.Line+1)
+            if wc.Continuation == "align" && toks[b.Open+1].Line == open.Line {
+                expected = tokenColumn(lines, open, tabWidth) + 1
+            } else {
+                expected = getIndent(openLine, tabWidth) + width
+            }
+        } else {
+            start := toks[b.StmtStart]
+            expected = getIndent(lines[start.Line], tabWidth) + width
+            construct = fmt.Sprintf("'%s' (line %d)", constructText(strings.TrimSpace(lines[start.Line])), start.Line+1)
+        }
+
+        if found := tokenColumn(lines, t, tabWidth); found != expected {
+            report(t, 0, len(leadingWhitespace(lines[t.Line])), ErrContinuationIndent, expected, construct, found)
+        }
+    }
+
+    if !wc.OneArgPerLine {
+        return
+    }
+    for _, c := range calls {
+        for _, comma := range c.Commas {
+            if next := toks[comma+1]; next.Line == toks[comma].Line {
+                report(next, next.Col, len(next.Text), ErrOneArgumentPerLine, toks[c.Open-1].Text)
+                break
+            }
+        }
+    }
+}
+
+/** ===============================================================
+ *                  P O I N T E R  S T Y L E
+ * ================================================================ */
+func tokensTouch(a, b cToken) bool {
+    return a.Line == b.Line && b.Col == a.Col+len(a.Text)
+}
+
+func isOperandEnd(t cToken) bool {
+    switch t.Kind {
+    case tokIdent:
+        return !keywords[t.Text]
+    case tokNumber, tokString, tokChar:
+        return true
+    }
+    return t.Text == ")" || t.Text == "]"
+}
+
+func isTypeTokenAt(toks []cToken, j int, typeNames map[string]bool) bool {
+    if j < 0 {
+        return false
+    }
+    t := toks[j]
+    if t.Kind != tokIdent {
+        return t.Text == "*"
+    }
+    switch {
+    case baseTypeWords[t.Text], typeNames[t.Text], wellKnownTypes[t.Text]:
+        return true
+    case t.Text == "const" || t.Text == "volatile" || t.Text == "restrict":
+        return isTypeTokenAt(toks, j-1, typeNames)
+    case keywords[t.Text]:
+        return false
+    case strings.HasSuffix(t.Text, "_t"):
+        return true
+    }
+    return j > 0 && hasWord(toks[j-1:j], "struct", "union", "enum")
+}
+
+func pointerExample(style, context string) string {
+    if context == "cast" {
+        if style == "left" {
+            return "(type*)"
+        }
+        return "(type *)"
+    }
+    switch style {
+    case "left":
+        return "type* name"
+    case "middle":
+        return "type * name"
+    }
+    return "type *name"
+}
+
+func pointerStars(model *sourceModel) map[int]map[int]bool {
+    toks := model.Tokens
+    typeNames := make(map[string]bool)
+    for _, id := range model.Idents {
+        if id.Kind == KindTypedef {
+            typeNames[id.Name] = true
+        }
+    }
+
+    stars := make(map[int]map[int]bool)
+    for i := 1; i < len(toks); i++ {
+        if toks[i].Text != "*" {
+            continue
+        }
+        first := i
+        for first > 0 && toks[first-1].Text == "*" {
+            first--
+        }
+        if !isTypeTokenAt(toks, first-1, typeNames) && isOperandEnd(toks[first-1]) {
+            continue
+        }
+        if stars[toks[i].Line] == nil {
+            stars[toks[i].Line] = make(map[int]bool)
+        }
+        stars[toks[i].Line][toks[i].Col] = true
+    }
+    return stars
+}
+
+func checkPointerStyle(
+    model *sourceModel,
+    ptr *PointerConfig,
+    errs *[]StyleError,
+) {
+    toks := model.Tokens
+    typeNames := make(map[string]bool)
+    for _, id := range model.Idents {
+        if id.Kind == KindTypedef {
+            typeNames[id.Name] = true
+        }
+    }
+
+    report := func(t cToken, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: t.Line + 1,
+            Start:   t.Col,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    for i := 1; i < len(toks); i++ {
+        if toks[i].Text != "*" || toks[i-1].Text == "*" {
+            continue
+        }
+        first, last := i, i
+        for last+1 < len(toks) && toks[last+1].Text == "*" {
+            last++
+        }
+        prev := toks[first-1]
+        if last+1 >= len(toks) {
+            break
+        }
+        next := toks[last+1]
+        star := toks[first]
+        length := toks[last].Col + 1 - star.Col
+
+        if !isTypeTokenAt(toks, first-1, typeNames) {
+            if isOperandEnd(prev) {
+                continue
+            }
+            if next.Kind == tokIdent && !tokensTouch(toks[last], next) && next.Line == star.Line {
+                context := "dereference"
+                if prev.Text == "(" && last+2 < len(toks) && toks[last+2].Text == ")" &&
+                    last+3 < len(toks) && toks[last+3].Text == "(" {
+                    context = "function pointer"
+                }
+                report(star, length, ErrPointerMustBeAttached, context)
+            }
+            continue
+        }
+
+        for k := first; k < last; k++ {
+            if !tokensTouch(toks[k], toks[k+1]) && toks[k].Line == toks[k+1].Line {
+                report(star, length, ErrPointerStarStyle, "declaration", pointerExample(ptr.Style, "declaration"), ptr.Style)
+                break
+            }
+        }
+
+        context := "declaration"
+        if next.Text == ")" || next.Text == "," {
+            context = "cast"
+        }
+
+        spaceBefore := !tokensTouch(prev, star)
+        spaceAfter := !tokensTouch(toks[last], next)
+        wantBefore := ptr.Style != "left"
+        wantAfter := ptr.Style != "right"
+        if context == "cast" {
+            wantAfter = false
+        }
+        if prev.Line != star.Line {
+            spaceBefore = wantBefore
+        }
+        if next.Line != star.Line {
+            spaceAfter = wantAfter
+        }
+        if spaceBefore != wantBefore || spaceAfter != wantAfter {
+            report(star, length, ErrPointerStarStyle, context, pointerExample(ptr.Style, context), ptr.Style)
+        }
+    }
+}
+
+/** ===============================================================
+ *                 S T Y L E  I N F E R E N C E
+ * ================================================================ */
+func (v styleVotes) add(setting, value string) {
+    if v[setting] == nil {
+        v[setting] = make(map[string]int)
+    }
+    v[setting][value]++
+}
+
+func (v styleVotes) dominant(setting string) (inferredSetting, bool) {
+    best, total := "", 0
+    for value, n := range v[setting] {
+        total += n
+        if best == "" || n > v[setting][best] || (n == v[setting][best] && value < best) {
+            best = value
+        }
+    }
+    if total == 0 {
+        return inferredSetting{}, false
+    }
+    confidence := math.Round(float64(v[setting][best])/float64(total)*100) / 100
+    return inferredSetting{Value: best, Confidence: confidence, Samples: total}, true
+}
+
+func caseStyleOf(name string) string {
+    switch {
+    case caseStylePatterns["MODULE_camelCase"].MatchString(name):
+        return "MODULE_camelCase"
+    case caseStylePatterns["SCREAMING_SNAKE_CASE"].MatchString(name):
+        if len(name) > 1 {
+            return "SCREAMING_SNAKE_CASE"
+        }
+    case caseStylePatterns["snake_case"].MatchString(name):
+        if strings.Contains(name, "_") {
+            return "snake_case"
+        }
+    case caseStylePatterns["camelCase"].MatchString(name):
+        return "camelCase"
+    case caseStylePatterns["PascalCase"].MatchString(name):
+        return "PascalCase"
+    }
+    return ""
+}
+
+func blockOwnerAt(toks []cToken, i int) (int, bool) {
+    if i == 0 {
+        return 0, false
+    }
+    prev := i - 1
+    switch toks[prev].Text {
+    case "else", "do":
+        return prev, true
+    case ")":
+        depth := 0
+        for j := prev; j >= 0; j-- {
+            switch toks[j].Text {
+            case ")":
+                depth++
+            case "(":
+                depth--
+            }
+            if depth == 0 {
+                if j > 0 && (toks[j-1].Text == "if" || toks[j-1].Text == "for" ||
+                    toks[j-1].Text == "while" || toks[j-1].Text == "switch") {
+                    return j - 1, true
+                }
+                return j, false
+            }
+        }
+        return prev, false
+    }
+    for j := prev; j >= 0 && j >= prev-2; j-- {
+        switch toks[j].Text {
+        case "struct", "union", "enum":
+            return j, true
+        }
+    }
+    return prev, false
+}
+
+func collectLayoutVotes(lines []string, model *sourceModel, votes styleVotes) {
+    const tabWidth = 8
+    toks := model.Tokens
+    continuation := continuationLines(model)
+    firstOnLine := make(map[int]bool)
+    for i, t := range toks {
+        if i == 0 || toks[i-1].Line != t.Line {
+            firstOnLine[i] = true
+        }
+    }
+    lineIndent := func(l int) int {
+        return getIndent(lines[l], tabWidth)
+    }
+
+    for i, t := range toks {
+        if !firstOnLine[i] || continuation[t.Line] {
+            continue
+        }
+        switch ws := leadingWhitespace(lines[t.Line]); {
+        case ws == "":
+        case strings.Trim(ws, " ") == "":
+            votes.add("indent.style", "spaces")
+        case strings.Trim(ws, "\t") == "":
+            votes.add("indent.style", "tabs")
+        case strings.TrimLeft(ws, "\t") != ws && strings.Trim(strings.TrimLeft(ws, "\t"), " ") == "":
+            votes.add("indent.style", "smart_tabs")
+        }
+    }
+
+    for i, t := range toks {
+        switch t.Text {
+        case "else":
+            if i > 0 && toks[i-1].Text == "}" {
+                if toks[i-1].Line == t.Line {
+                    votes.add("else", "cuddled")
+                } else {
+                    votes.add("else", "own_line")
+                }
+            }
+        case "switch":
+            depth := 0
+            for j := i + 1; j < len(toks); j++ {
+                if toks[j].Text == "{" {
+                    depth++
+                } else if toks[j].Text == "}" {
+                    if depth--; depth <= 0 {
+                        break
+                    }
+                }
+                if depth == 1 && (toks[j].Text == "case" || toks[j].Text == "default") && firstOnLine[j] {
+                    if lineIndent(toks[j].Line) > lineIndent(t.Line) {
+                        votes.add("indent.indent_case_labels", "true")
+                    } else {
+                        votes.add("indent.indent_case_labels", "false")
+                    }
+                    break
+                }
+            }
+        case "{":
+            if i+1 >= len(toks) {
+                continue
+            }
+            owner, control := blockOwnerAt(toks, i)
+            ownerIndent := lineIndent(toks[owner].Line)
+            braceIndent := lineIndent(t.Line)
+            body := toks[i+1]
+            bodyIndent := -1
+            if body.Line != t.Line && firstOnLine[i+1] && body.Text != "}" {
+                bodyIndent = lineIndent(body.Line)
+            }
+
+            if control {
+                switch {
+                case t.Line == toks[owner].Line:
+                    votes.add("brace", "same_line")
+                case !firstOnLine[i]:
+                case braceIndent == ownerIndent:
+                    votes.add("brace", "own_line")
+                case braceIndent > ownerIndent:
+                    votes.add("brace", "indented")
+                    if bodyIndent == braceIndent {
+                        votes.add("brace_body", "aligned")
+                    } else if bodyIndent > braceIndent {
+                        votes.add("brace_body", "indented")
+                    }
+                }
+            }
+
+            if firstOnLine[i] && t.Line != toks[owner].Line && braceIndent > ownerIndent {
+                votes.add("indent.width", strconv.Itoa(braceIndent-ownerIndent))
+            } else if bodyIndent > braceIndent {
+                votes.add("indent.width", strconv.Itoa(bodyIndent-braceIndent))
+            }
+        }
+    }
+}
+
+func collectPointerVotes(model *sourceModel, votes styleVotes) {
+    toks := model.Tokens
+    typeNames := make(map[string]bool)
+    for _, id := range model.Idents {
+        if id.Kind == KindTypedef {
+            typeNames[id.Name] = true
+        }
+    }
+    for i := 1; i+1 < len(toks); i++ {
+        if toks[i].Text != "*" || toks[i-1].Text == "*" || !isTypeTokenAt(toks, i-1, typeNames) {
+            continue
+        }
+        last := i
+        for last+1 < len(toks) && toks[last+1].Text == "*" {
+            last++
+        }
+        if last+1 >= len(toks) || toks[last+1].Kind != tokIdent || toks[last+1].Line != toks[i].Line ||
+            toks[i-1].Line != toks[i].Line {
+            continue
+        }
+        before := !tokensTouch(toks[i-1], toks[i])
+        after := !tokensTouch(toks[last], toks[last+1])
+        switch {
+        case before && !after:
+            votes.add("pointer.style", "right")
+        case !before && after:
+            votes.add("pointer.style", "left")
+        case before && after:
+            votes.add("pointer.style", "middle")
+        }
+    }
+}
+
+func collectNamingVotes(filename string, model *sourceModel, votes styleVotes) {
+    rules := defaultConfig().Naming.rules()
+    module := moduleName(filename, &ModuleConfig{Enabled: true, Source: "file"})
+    header := isHeaderFile(filename)
+    defs := functionDefinitions(model)
+    for _, id := range model.Idents {
+        key, rule := namingConfigKeys[id.Kind], rules[id.Kind]
+        if key == "" || isExcludedName(rule, id.Name) {
+            continue
+        }
+        core := id.Name
+        if rule.Suffix != "" && strings.HasSuffix(core, rule.Suffix) && core != rule.Suffix {
+            core = strings.TrimSuffix(core, rule.Suffix)
+        }
+        if cs := caseStyleOf(core); cs != "" {
+            votes.add("naming."+key+".case", cs)
+        }
+        if requiresModulePrefix(id.Kind, header, defs[[2]int{id.Line, id.Col}]) {
+            votes.add("naming.module.enabled",
+                strconv.FormatBool(strings.HasPrefix(strings.ToUpper(id.Name), module)))
+        }
+    }
+}
+
+func inferStyle(files []string) (*styleInference, error) {
+    votes := make(styleVotes)
+    for _, f := range files {
+        raw, err := os.ReadFile(f)
+        if err != nil {
+            return nil, err
+        }
+        lines := strings.Split(string(raw), "\n")
+        model := parseSource(lines)
+        collectLayoutVotes(lines, model, votes)
+        collectPointerVotes(model, votes)
+        collectNamingVotes(f, model, votes)
+    }
+
+    inf := &styleInference{Style: StyleKR, Settings: make(map[string]inferredSetting)}
+    for setting := range votes {
+        if s, ok := votes.dominant(setting); ok {
+            inf.Settings[setting] = s
+        }
+    }
+
+    brace, elsePlacement := inf.Settings["brace"], inf.Settings["else"]
+    decided := inferredSetting{Confidence: 1}
+    weakest := func(settings ...inferredSetting) {
+        for _, s := range settings {
+            if s.Samples > 0 && s.Confidence < decided.Confidence {
+                decided.Confidence = s.Confidence
+            }
+            decided.Samples += s.Samples
+        }
+    }
+    switch brace.Value {
+    case "indented":
+        inf.Style = StyleGNU
+        if inf.Settings["brace_body"].Value == "aligned" {
+            inf.Style = StyleWhitesmiths
+        }
+        weakest(brace, inf.Settings["brace_body"])
+    case "own_line":
+        inf.Style = StyleAllman
+        weakest(brace)
+    default:
+        switch {
+        case elsePlacement.Value == "own_line":
+            inf.Style = StyleStroustrup
+        case inf.Settings["indent.style"].Value == "tabs":
+            inf.Style = StyleLinux
+        }
+        weakest(brace, elsePlacement, inf.Settings["indent.style"])
+    }
+    if decided.Samples == 0 {
+        decided.Confidence = 0
+    }
+    for name, mode := range styleModes {
+        if mode == inf.Style {
+            decided.Value = name
+        }
+    }
+    inf.Settings["style"] = decided
+    return inf, nil
+}
+
+func (inf *styleInference) apply(cfg *Config) {
+    inf.Style.applyDefaults(cfg)
+    rules := cfg.Naming.rules()
+    for setting, s := range inf.Settings {
+        switch parts := strings.Split(setting, "."); {
+        case setting == "indent.style":
+            cfg.Indent.Style = s.Value
+        case setting == "indent.width":
+            cfg.Indent.Width, _ = strconv.Atoi(s.Value)
+        case setting == "indent.indent_case_labels":
+            cfg.Indent.IndentCaseLabels = s.Value == "true"
+        case setting == "pointer.style":
+            cfg.Pointer.Style = s.Value
+        case setting == "naming.module.enabled":
+            cfg.Naming.Module.Enabled = s.Value == "true"
+        case len(parts) == 3 && parts[0] == "naming":
+            for kind, key := range namingConfigKeys {
+                if key == parts[1] {
+                    rules[kind].Case = s.Value
+                }
+            }
+        }
+    }
+    if cfg.Indent.Style != "spaces" && inf.Settings["indent.width"].Samples > 0 {
+        cfg.Indent.TabWidth = cfg.Indent.Width
+    }
+}
+
+func (inf *styleInference) configJSON() ([]byte, error) {
+    out := map[string]interface{}{"inferred": inf.Settings}
+    for setting, s := range inf.Settings {
+        parts := strings.Split(setting, ".")
+        if len(parts) < 2 {
+            continue
+        }
+        node := out
+        for _, p := range parts[:len(parts)-1] {
+            child, ok := node[p].(map[string]interface{})
+            if !ok {
+                child = make(map[string]interface{})
+                node[p] = child
+            }
+            node = child
+        }
+        var value interface{} = s.Value
+        if n, err := strconv.Atoi(s.Value); err == nil {
+            value = n
+        } else if b, err := strconv.ParseBool(s.Value); err == nil {
+            value = b
+        }
+        node[parts[len(parts)-1]] = value
+    }
+    if w, ok := inf.Settings["indent.width"]; ok && inf.Settings["indent.style"].Value != "spaces" {
+        out["indent"].(map[string]interface{})["tab_width"], _ = strconv.Atoi(w.Value)
+    }
+    return json.MarshalIndent(out, "", "  ")
+}
+
+func printInference(w io.Writer, inf *styleInference, nFiles int) {
+    settings := make([]string, 0, len(inf.Settings))
+    for setting := range inf.Settings {
+        settings = append(settings, setting)
+    }
+    sort.Strings(settings)
+
+    fmt.Fprintf(w, "Inferred style from %d file(s): --style=%s\n", nFiles, inf.Settings["style"].Value)
+    fmt.Fprintf(w, "  %-28s %-22s %10s %8s\n", "setting", "value", "confidence", "samples")
+    for _, setting := range settings {
+        s := inf.Settings[setting]
+        fmt.Fprintf(w, "  %-28s %-22s %9.0f%% %8d\n", setting, s.Value, s.Confidence*100, s.Samples)
+    }
+}
+
+func inferConfig(files []string) error {
+    inf, err := inferStyle(files)
+    if err != nil {
+        return err
+    }
+    data, err := inf.configJSON()
+    if err != nil {
+        return err
+    }
+    printInference(os.Stderr, inf, len(files))
+    fmt.Println(string(data))
+    return nil
+}
+
+/** ===============================================================
+ *                L A N G U A G E  S T A N D A R D
+ * ================================================================ */
+func parseStd(s string) (LangStd, error) {
+    if std, ok := langStds[strings.ToLower(s)]; ok {
+        return std, nil
+    }
+    return 0, fmt.Errorf("invalid standard: %q (use \"c89\", \"c99\", \"c11\", \"c17\" or \"c23\")", s)
+}
+
+func (s LangStd) String() string {
+    return [...]string{"C89", "C99", "C11", "C17", "C23"}[s]
+}
+
+func languageKeywords(std LangStd) map[string]bool {
+    words := make(map[string]bool)
+    for word, since := range stdKeywords {
+        if since <= std {
+            words[word] = true
+        }
+    }
+    return words
+}
+
+func (s LangStd) apply() {
+    keywords = languageKeywords(s)
+    typesMap["bool"] = s >= StdC99
+}
+
+func featureStd(word string) (LangStd, bool) {
+    if std, ok := stdHeaderWords[word]; ok {
+        return std, true
+    }
+    std, ok := stdKeywords[word]
+    return std, ok
+}
+
+func stripAttributes(stmt []cToken) []cToken {
+    var out []cToken
+    for i := 0; i < len(stmt); i++ {
+        if stmt[i].Text == "[" && i+1 < len(stmt) && stmt[i+1].Text == "[" {
+            i = matchBracket(stmt, i)
+            continue
+        }
+        out = append(out, stmt[i])
+    }
+    return out
+}
+
+func checkStandard(
+    model *sourceModel,
+    std LangStd,
+    errs *[]StyleError,
+) {
+    report := func(line, col, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: line + 1,
+            Start:   col,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    declared := make(map[string]bool)
+    for _, id := range model.Idents {
+        declared[id.Name] = true
+    }
+
+    toks := model.Tokens
+    for i := 0; i < len(toks); i++ {
+        t := toks[i]
+        if t.Text == "[" && i+1 < len(toks) && toks[i+1].Text == "[" && tokensTouch(t, toks[i+1]) {
+            if std < StdC23 {
+                report(t.Line, t.Col, 2, ErrFeatureNotInStd, "'[[...]]' attributes", StdC23, std)
+            }
+            i = matchBracket(toks, i)
+            continue
+        }
+        if t.Kind != tokIdent || declared[t.Text] {
+            continue
+        }
+        if since, ok := featureStd(t.Text); ok && since > std {
+            report(t.Line, t.Col, len(t.Text), ErrFeatureNotInStd, "'"+t.Text+"'", since, std)
+        }
+        if repl, ok := obsolescentSpellings[t.Text]; ok && std >= StdC23 {
+            report(t.Line, t.Col, len(t.Text), WarnObsolescentSpelling, t.Text, repl)
+        }
+    }
+
+    if std < StdC99 {
+        for _, c := range model.Comments {
+            if !c.Block {
+                report(c.Line, c.Col, 2, ErrFeatureNotInStd, "'//' comments", StdC99, std)
+            }
+        }
+        checkMixedDeclarations(model, std, errs)
+    }
+}
+
+func checkMixedDeclarations(
+    model *sourceModel,
+    std LangStd,
+    errs *[]StyleError,
+) {
+    toks := model.Tokens
+    p := &declParser{model: model, typedefs: make(map[string]bool)}
+    for _, id := range model.Idents {
+        if id.Kind == KindTypedef {
+            p.typedefs[id.Name] = true
+        }
+    }
+
+    report := func(stmt []cToken, where string) {
+        name := stmt[0]
+        _, rest := p.splitSpecifiers(stmt)
+        if parts := splitTopLevel(rest, ","); len(parts) > 0 {
+            if d, ok := parseDeclarator(parts[0]); ok {
+                name = d.name
+            }
+        }
+        *errs = append(*errs, StyleError{
+            LineNum: name.Line + 1,
+            Start:   name.Col,
+            Length:  len(name.Text),
+            Message: FormatMessage(ErrMixedDeclaration, name.Text, where, std),
+            Level:   FormatErrorLevel(ErrMixedDeclaration),
+            Code:    ErrMixedDeclaration,
+        })
+    }
+
+    for _, fn := range model.Functions {
+        if !fn.IsDef || fn.BodyEnd <= fn.BodyStart {
+            continue
+        }
+        sawStmt := []bool{false}
+        var stmt []cToken
+        depth := 0
+        for i := fn.BodyStart + 1; i < fn.BodyEnd; i++ {
+            t := toks[i]
+            top := len(sawStmt) - 1
+            switch {
+            case t.Text == "(" && depth == 0 && len(stmt) == 1 && stmt[0].Text == "for":
+                end := i + 1
+                for end < fn.BodyEnd && toks[end].Text != ";" {
+                    end++
+                }
+                if init := toks[i+1 : end]; p.looksLikeDecl(init, false) {
+                    report(init, "in a 'for' initializer")
+                }
+                depth++
+            case t.Text == "(" || t.Text == "[":
+                depth++
+            case t.Text == ")" || t.Text == "]":
+                depth--
+            case depth > 0:
+            case t.Text == "{":
+                if hasTopLevel(stmt, "=") || (len(stmt) > 0 && (stmt[0].Text == "struct" ||
+                    stmt[0].Text == "union" || stmt[0].Text == "enum")) {
+                    i = matchBracket(toks, i)
+                    continue
+                }
+                if len(stmt) > 0 {
+                    sawStmt[top] = true
+                }
+                sawStmt = append(sawStmt, false)
+                stmt = nil
+                continue
+            case t.Text == "}":
+                if len(sawStmt) > 1 {
+                    sawStmt = sawStmt[:top]
+                }
+                sawStmt[len(sawStmt)-1] = true
+                stmt = nil
+                continue
+            case t.Text == ";":
+                stmt = stripAttributes(stmt)
+                if p.looksLikeDecl(stmt, false) {
+                    if sawStmt[top] {
+                        report(stmt, "after a statement")
+                    }
+                } else if len(stmt) > 0 {
+                    sawStmt[top] = true
+                }
+                stmt = nil
+                continue
+            case t.Text == ":" && (len(stmt) == 1 || (len(stmt) > 0 && (stmt[0].Text == "case" || stmt[0].Text == "default"))):
+                sawStmt[top] = true
+                stmt = nil
+                continue
+            }
+            stmt = append(stmt, t)
+        }
+    }
+}
+
+/** ===============================================================
+ *                    M I S R A  C : 2 0 1 2
+ * ================================================================ */
+func misraIdentRule(kind EntityKind) string {
+    switch {
+    case kind == KindMacro || kind == KindFunctionMacro:
+        return "5.4"
+    case kind == KindFunction || kind == KindGlobal:
+        return "5.1"
+    }
+    return "5.2"
+}
+
+func isMisraUnsigned(text string) bool {
+    body := strings.TrimRight(text, "uUlL")
+    suffix := strings.ToLower(text[len(body):])
+    if strings.Contains(suffix, "u") || len(body) < 2 || body[0] != '0' {
+        return false
+    }
+    v, err := strconv.ParseUint(body, 0, 64)
+    if err != nil {
+        return false
+    }
+    return (v >= 1<<31 && v < 1<<32 && suffix != "ll") || v >= 1<<63
+}
+
+func checkMisraNumbers(toks []cToken, on func(string) bool, errs *[]StyleError) {
+    for _, t := range toks {
+        if t.Kind != tokNumber {
+            continue
+        }
+        var code ErrorCode = -1
+        switch {
+        case on("7.1") && reOctalConst.MatchString(t.Text):
+            code = ErrMisraOctalConstant
+        case on("7.2") && isMisraUnsigned(t.Text):
+            code = ErrMisraUnsignedSuffix
+        }
+        if code < 0 {
+            continue
+        }
+        *errs = append(*errs, StyleError{
+            LineNum: t.Line + 1,
+            Start:   t.Col,
+            Length:  len(t.Text),
+            Message: FormatMessage(code, t.Text),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+}
+
+func checkMisra(
+    model *sourceModel,
+    misra *MisraConfig,
+    errs *[]StyleError,
+) {
+    if !misra.Enabled {
+        return
+    }
+    deviated := make(map[string]bool, len(misra.Deviations))
+    for _, rule := range misra.Deviations {
+        deviated[rule] = true
+    }
+    on := func(rule string) bool { return !deviated[rule] }
+
+    report := func(t cToken, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: t.Line + 1,
+            Start:   t.Col,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    toks := model.Tokens
+    checkMisraNumbers(toks, on, errs)
+    for _, d := range model.Directives {
+        checkMisraNumbers(d.Tokens, on, errs)
+    }
+
+    for i, t := range toks {
+        switch t.Text {
+        case "if", "for", "while", "switch":
+            if !on("15.6") || i+1 >= len(toks) || toks[i+1].Text != "(" {
+                continue
+            }
+            end := matchBracket(toks, i+1)
+            if end+1 >= len(toks) || toks[end+1].Text == "{" {
+                continue
+            }
+            if t.Text == "while" && toks[end+1].Text == ";" && i > 0 && toks[i-1].Text == "}" {
+                continue
+            }
+            report(t, len(t.Text), ErrMisraCompoundBody, t.Text)
+        case "else", "do":
+            if on("15.6") && i+1 < len(toks) && toks[i+1].Text != "{" && toks[i+1].Text != "if" {
+                report(t, len(t.Text), ErrMisraCompoundBody, t.Text)
+            }
+        }
+    }
+
+    for _, fn := range model.Functions {
+        if !fn.IsDef || fn.BodyEnd <= fn.BodyStart {
+            continue
+        }
+        body := toks[fn.BodyStart:fn.BodyEnd]
+
+        labels := make(map[string]int)
+        for k := 1; k+1 < len(body); k++ {
+            if body[k].Kind == tokIdent && body[k+1].Text == ":" && !keywords[body[k].Text] &&
+                (body[k-1].Text == ";" || body[k-1].Text == "{" || body[k-1].Text == "}" || body[k-1].Text == ":") {
+                labels[body[k].Text] = k
+            }
+        }
+
+        for k, t := range body {
+            switch t.Text {
+            case "goto":
+                if k+1 >= len(body) || !on("15.2") {
+                    continue
+                }
+                if at, ok := labels[body[k+1].Text]; ok && at < k {
+                    report(t, len("goto"), ErrMisraBackwardGoto, body[k+1].Text, body[at].Line+1)
+                }
+            case "return":
+                if !on("15.5") {
+                    continue
+                }
+                end := k
+                for end < len(body) && body[end].Text != ";" {
+                    end++
+                }
+                if fn.BodyStart+end+1 != fn.BodyEnd {
+                    report(t, len("return"), WarnMisraSingleExit, fn.Name)
+                }
+            case "switch":
+                if !on("16.5") || k+1 >= len(body) || body[k+1].Text != "(" {
+                    continue
+                }
+                open := matchBracket(body, k+1) + 1
+                if open >= len(body) || body[open].Text != "{" {
+                    continue
+                }
+                labels := switchLabels(body, open)
+                for n, at := range labels {
+                    if body[at].Text == "default" && n != 0 && n != len(labels)-1 {
+                        report(body[at], len("default"), ErrMisraDefaultPosition)
+                    }
+                }
+            }
+        }
+    }
+
+    if on("Dir 4.9") {
+        for _, d := range model.Directives {
+            if len(d.Tokens) < 5 || d.Tokens[1].Text != "define" || d.Tokens[3].Text != "(" ||
+                !tokensTouch(d.Tokens[2], d.Tokens[3]) {
+                continue
+            }
+            closing := matchBracket(d.Tokens, 3)
+            if closing >= len(d.Tokens) {
+                continue
+            }
+            replacement := d.Tokens[closing+1:]
+            needsMacro := len(replacement) == 0
+            for _, t := range replacement {
+                switch t.Text {
+                case "#", "##", "__FILE__", "__LINE__", "__func__", "return", "break", "continue", "goto":
+                    needsMacro = true
+                }
+            }
+            if !needsMacro {
+                report(d.Tokens[2], len(d.Tokens[2].Text), WarnMisraFunctionMacro, d.Tokens[2].Text)
+            }
+        }
+    }
+
+    n := misra.SignificantChars
+    seen := make(map[string]cIdent)
+    for _, id := range model.Idents {
+        if len(id.Name) <= n {
+            continue
+        }
+        rule := misraIdentRule(id.Kind)
+        if !on(rule) {
+            continue
+        }
+        key := rule + ":" + id.Name[:n]
+        if prev, ok := seen[key]; ok && prev.Name != id.Name {
+            report(cToken{Line: id.Line, Col: id.Col}, len(id.Name), ErrMisraIdentifierNotDistinct,
+                rule, entityKindNames[id.Kind], id.Name, prev.Name, prev.Line+1, n)
+            continue
+        }
+        seen[key] = id
+    }
+}
+
+/** ===============================================================
+ *                   P O W E R  O F  T E N
+ * ================================================================ */
+func matchesAnyPattern(name string, patterns []string) bool {
+    for _, pat := range patterns {
+        if ok, _ := filepath.Match(pat, name); ok {
+            return true
+        }
+    }
+    return false
+}
+
+func isStatementStart(toks []cToken, i int) bool {
+    if i == 0 {
+        return true
+    }
+    prev := toks[i-1]
+    switch prev.Text {
+    case ";", "{", "}", "else", "do":
+        return true
+    case ":":
+        for j := i - 2; j >= 0; j-- {
+            switch toks[j].Text {
+            case "?":
+                return false
+            case ";", "{", "}":
+                return true
+            }
+        }
+        return true
+    case ")":
+        depth := 0
+        for j := i - 1; j >= 0; j-- {
+            switch toks[j].Text {
+            case ")":
+                depth++
+            case "(":
+                depth--
+            }
+            if depth == 0 {
+                if j == 0 {
+                    return false
+                }
+                switch toks[j-1].Text {
+                case "if", "for", "while":
+                    return true
+                }
+                return false
+            }
+        }
+    }
+    return false
+}
+
+func callGraph(model *sourceModel) map[string][]string {
+    toks := model.Tokens
+    defined := make(map[string]bool)
+    for _, fn := range model.Functions {
+        if fn.IsDef {
+            defined[fn.Name] = true
+        }
+    }
+    graph := make(map[string][]string)
+    for _, fn := range model.Functions {
+        if !fn.IsDef || fn.BodyEnd <= fn.BodyStart {
+            continue
+        }
+        seen := make(map[string]bool)
+        for i := fn.BodyStart + 1; i+1 < fn.BodyEnd; i++ {
+            name := toks[i].Text
+            if toks[i].Kind == tokIdent && toks[i+1].Text == "(" && defined[name] && !seen[name] &&
+                toks[i-1].Text != "." && toks[i-1].Text != "->" {
+                seen[name] = true
+                graph[fn.Name] = append(graph[fn.Name], name)
+            }
+        }
+    }
+    return graph
+}
+
+func recursionPath(graph map[string][]string, fn string) []string {
+    parent := map[string]string{}
+    queue := []string{fn}
+    for len(queue) > 0 {
+        cur := queue[0]
+        queue = queue[1:]
+        for _, callee := range graph[cur] {
+            if callee == fn {
+                path := []string{fn}
+                for at := cur; at != fn; at = parent[at] {
+                    path = append([]string{at}, path...)
+                }
+                return append([]string{fn}, path...)
+            }
+            if _, ok := parent[callee]; !ok {
+                parent[callee] = cur
+                queue = append(queue, callee)
+            }
+        }
+    }
+    return nil
+}
+
+func loopBound(cond []cToken, kind string) bool {
+    parts := splitTopLevel(cond, ";")
+    if kind == "for" {
+        if len(parts) != 3 || len(parts[2]) == 0 {
+            return false
+        }
+        cond = parts[1]
+    }
+    for _, t := range cond {
+        switch t.Text {
+        case "<", "<=", ">", ">=":
+            return true
+        }
+    }
+    return false
+}
+
+func checkPowerOfTen(
+    model *sourceModel,
+    p10 *PowerOfTenConfig,
+    errs *[]StyleError,
+) {
+    report := func(t cToken, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: t.Line + 1,
+            Start:   t.Col,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    toks := model.Tokens
+    returnTypes := make(map[string]string)
+    for _, fn := range model.Functions {
+        returnTypes[fn.Name] = fn.ReturnType
+    }
+    var graph map[string][]string
+    if p10.NoRecursion {
+        graph = callGraph(model)
+    }
+
+    for _, fn := range model.Functions {
+        if !fn.IsDef || fn.BodyEnd <= fn.BodyStart {
+            continue
+        }
+        name := cToken{Line: fn.Line, Col: fn.Col}
+        initFunc := matchesAnyPattern(fn.Name, p10.InitFunctions)
+        asserts := 0
+
+        for i := fn.BodyStart + 1; i < fn.BodyEnd; i++ {
+            t := toks[i]
+            if t.Kind != tokIdent {
+                continue
+            }
+            call := i+1 < fn.BodyEnd && toks[i+1].Text == "("
+            switch {
+            case p10.NoGoto && (t.Text == "goto" || (call && (t.Text == "setjmp" || t.Text == "longjmp"))):
+                report(t, len(t.Text), ErrP10Goto, t.Text)
+            case p10.BoundedLoops && (t.Text == "for" || t.Text == "while") && call:
+                if end := matchBracket(toks, i+1); !loopBound(toks[i+2:end], t.Text) {
+                    report(t, len(t.Text), ErrP10UnboundedLoop, t.Text)
+                }
+            case p10.NoDynamicAlloc && call && dynamicAllocFuncs[t.Text] && !initFunc:
+                report(t, len(t.Text), ErrP10DynamicAlloc, t.Text, fn.Name)
+            case call && matchesAnyPattern(t.Text, p10.AssertMacros):
+                asserts++
+            case p10.CheckReturnValues && call && isStatementStart(toks, i):
+                ret, known := returnTypes[t.Text]
+                if !known {
+                    ret, known = "int", statusReturningFuncs[t.Text]
+                }
+                if end := matchBracket(toks, i+1); known && !isVoidType(ret) &&
+                    end+1 < len(toks) && toks[end+1].Text == ";" {
+                    report(t, len(t.Text), ErrP10UncheckedReturn, t.Text)
+                }
+            }
+        }
+
+        if p10.NoRecursion {
+            if path := recursionPath(graph, fn.Name); path != nil {
+                report(name, len(fn.Name), ErrP10Recursion, fn.Name, strings.Join(path, " -> "))
+            }
+        }
+        if p10.FunctionLength {
+            if lines := toks[fn.BodyEnd].Line - fn.StartLine + 1; lines > p10.MaxFunctionLines {
+                report(name, len(fn.Name), ErrP10FunctionTooLong, fn.Name, lines, p10.MaxFunctionLines)
+            }
+        }
+        if p10.AssertionDensity && asserts < p10.MinAssertions {
+            report(name, len(fn.Name), ErrP10AssertionDensity, fn.Name, asserts, p10.MinAssertions)
+        }
+    }
+}
+
+/** ===============================================================
+ *                        C E R T  C
+ * ================================================================ */
+
+func macroNames(model *sourceModel) (object, function map[string]bool) {
+    object = make(map[string]bool)
+    function = make(map[string]bool)
+    for _, d := range model.Directives {
+        t := d.Tokens
+        if len(t) < 3 || t[1].Text != "define" || t[2].Kind != tokIdent {
+            continue
+        }
+        if len(t) > 3 && t[3].Text == "(" && tokensTouch(t[2], t[3]) {
+            function[t[2].Text] = true
+        } else {
+            object[t[2].Text] = true
+        }
+    }
+    return object, function
+}
+
+func pointerNames(model *sourceModel) map[string]bool {
+    toks := model.Tokens
+    typeNames := make(map[string]bool)
+    for _, id := range model.Idents {
+        if id.Kind == KindTypedef {
+            typeNames[id.Name] = true
+        }
+    }
+    pointers := make(map[string]bool)
+    arrays := make(map[string]bool)
+    for i := 1; i+2 < len(toks); i++ {
+        name, next := toks[i+1], toks[i+2].Text
+        if name.Kind != tokIdent || keywords[name.Text] || !isTypeTokenAt(toks, i, typeNames) {
+            continue
+        }
+        switch {
+        case toks[i].Text == "*" && (next == ";" || next == "," || next == "=" || next == ")"):
+            pointers[name.Text] = true
+        case next == "[":
+            arrays[name.Text] = true
+        }
+    }
+    for _, fn := range model.Functions {
+        for _, prm := range fn.Params {
+            if strings.Contains(prm.Type, "*") || strings.Contains(prm.Type, "[") {
+                pointers[prm.Name] = true
+                delete(arrays, prm.Name)
+            }
+        }
+    }
+    for name := range arrays {
+        delete(pointers, name)
+    }
+    return pointers
+}
+
+func beforeCall(toks []cToken, i int) int {
+    j := i - 1
+    if j > 0 && toks[j].Text == ")" {
+        depth := 0
+        for k := j; k >= 0; k-- {
+            if toks[k].Text == ")" {
+                depth++
+            } else if toks[k].Text == "(" {
+                depth--
+            }
+            if depth == 0 {
+                if k+1 < j && isTypeTokenAt(toks, j-1, nil) {
+                    j = k - 1
+                }
+                break
+            }
+        }
+    }
+    return j
+}
+
+func exprText(toks []cToken) string {
+    var sb strings.Builder
+    for _, t := range toks {
+        sb.WriteString(t.Text)
+    }
+    return sb.String()
+}
+
+func nullChecked(toks []cToken, from, end int, name string) bool {
+    for k := from; k < end; k++ {
+        if toks[k].Text != name || toks[k].Kind != tokIdent {
+            continue
+        }
+        prev, next := toks[k-1].Text, toks[k+1].Text
+        switch {
+        case prev == "." || prev == "->":
+            continue
+        case prev == "!" || prev == "==" || prev == "!=" || prev == "&&" || prev == "||" || prev == "return":
+            return true
+        case next == "==" || next == "!=" || next == "&&" || next == "||" || next == "?":
+            return true
+        case prev == "(" && next == ")":
+            owner := toks[k-2].Text
+            return owner == "if" || owner == "while" || owner == "assert"
+        }
+        return false
+    }
+    return true
+}
+
+func signalHandlers(model *sourceModel, defined map[string]int) map[string]cToken {
+    toks := model.Tokens
+    handlers := make(map[string]cToken)
+    for i := 0; i+3 < len(toks); i++ {
+        switch {
+        case toks[i].Text == "signal" && toks[i+1].Text == "(":
+            end := matchBracket(toks, i+1)
+            args := splitTopLevel(toks[i+2:end], ",")
+            if len(args) == 2 && len(args[1]) == 1 {
+                if _, ok := defined[args[1][0].Text]; ok {
+                    handlers[args[1][0].Text] = toks[i]
+                }
+            }
+        case (toks[i].Text == "sa_handler" || toks[i].Text == "sa_sigaction") && toks[i+1].Text == "=":
+            if _, ok := defined[toks[i+2].Text]; ok && toks[i+3].Text == ";" {
+                handlers[toks[i+2].Text] = toks[i]
+            }
+        }
+    }
+    return handlers
+}
+
+func checkCert(
+    model *sourceModel,
+    cert *CertConfig,
+    errs *[]StyleError,
+) {
+    if !cert.Enabled {
+        return
+    }
+    deviated := make(map[string]bool, len(cert.Deviations))
+    for _, rule := range cert.Deviations {
+        deviated[rule] = true
+    }
+    on := func(rule string) bool { return !deviated[rule] }
+
+    report := func(t cToken, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: t.Line + 1,
+            Start:   t.Col,
+            Length:  len(t.Text),
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    toks := model.Tokens
+    objectMacros, funcMacros := macroNames(model)
+    pointers := pointerNames(model)
+    defined := make(map[string]int)
+    for i, fn := range model.Functions {
+        if fn.IsDef && fn.BodyEnd > fn.BodyStart {
+            defined[fn.Name] = i
+        }
+    }
+
+    for _, fn := range model.Functions {
+        if !fn.IsDef || fn.BodyEnd <= fn.BodyStart {
+            continue
+        }
+        params := make(map[string]string)
+        for _, prm := range fn.Params {
+            params[prm.Name] = prm.Type
+        }
+        variadic := false
+        for j := fn.BodyStart - 1; j >= 0 && (toks[j].Line != fn.Line || toks[j].Col != fn.Col); j-- {
+            variadic = variadic || toks[j].Text == "..."
+        }
+
+        for i := fn.BodyStart + 1; i+1 < fn.BodyEnd; i++ {
+            t := toks[i]
+            if t.Kind != tokIdent || toks[i+1].Text != "(" || toks[i-1].Text == "." || toks[i-1].Text == "->" {
+                continue
+            }
+            end := matchBracket(toks, i+1)
+            args := splitTopLevel(toks[i+2:end], ",")
+
+            if idx, ok := certFormatArg[t.Text]; ok && on("FIO30-C") && idx < len(args) && len(args[idx]) > 0 {
+                f := args[idx][0]
+                vaList := false
+                for _, a := range args[idx+1:] {
+                    vaList = vaList || len(a) == 1 && params[a[0].Text] == "va_list"
+                }
+                _, isParam := params[f.Text]
+                wrapper := len(args[idx]) == 1 && isParam && (variadic || vaList && strings.HasPrefix(t.Text, "v"))
+                literal := f.Kind == tokString || objectMacros[f.Text] || wrapper ||
+                    (len(args[idx]) > 1 && args[idx][1].Text == "(" && certTranslationFuncs[f.Text])
+                if !literal {
+                    report(t, ErrCertFormatString, t.Text)
+                }
+            }
+            if idx, ok := certSizeArg[t.Text]; ok && on("ARR01-C") && idx < len(args) {
+                size := args[idx]
+                if len(size) == 4 && size[1].Text == "(" && size[3].Text == ")" {
+                    size = []cToken{size[0], size[2]}
+                }
+                if len(size) == 2 && size[0].Text == "sizeof" && pointers[size[1].Text] {
+                    report(size[0], ErrCertSizeofPointer, t.Text, size[1].Text)
+                }
+            }
+            if repl, ok := certConversionFuncs[t.Text]; ok && on("ERR34-C") {
+                report(t, WarnCertUncheckedConversion, t.Text, repl)
+            }
+
+            switch t.Text {
+            case "realloc":
+                eq := beforeCall(toks, i)
+                if !on("MEM31-C") || toks[eq].Text != "=" || len(args) != 2 {
+                    continue
+                }
+                lhs := args[0]
+                start := eq - len(lhs)
+                if len(lhs) == 0 || start <= fn.BodyStart {
+                    continue
+                }
+                same := true
+                for k, a := range lhs {
+                    if toks[start+k].Text != a.Text {
+                        same = false
+                        break
+                    }
+                }
+                if before := toks[start-1]; same && before.Kind != tokIdent && before.Text != "." && before.Text != "->" {
+                    expr := exprText(lhs)
+                    report(t, ErrCertReallocLeak, expr, expr)
+                }
+            case "malloc", "calloc", "aligned_alloc":
+                if !on("ERR33-C") || isStatementStart(toks, i) {
+                    continue
+                }
+                switch j := beforeCall(toks, i); {
+                case toks[j].Text == "return":
+                case toks[j].Text != "=":
+                    report(t, ErrCertUncheckedAlloc, t.Text)
+                case toks[j-1].Kind == tokIdent && toks[end+1].Text != ")":
+                    if !nullChecked(toks, end+1, fn.BodyEnd, toks[j-1].Text) {
+                        report(t, ErrCertUncheckedAlloc, t.Text)
+                    }
+                }
+            }
+        }
+    }
+
+    if !on("SIG30-C") {
+        return
+    }
+    handlers := signalHandlers(model, defined)
+    names := make([]string, 0, len(handlers))
+    for name := range handlers {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, handler := range names {
+        reg := handlers[handler]
+        visited := map[string]bool{handler: true}
+        queue := []string{handler}
+        for len(queue) > 0 {
+            fn := model.Functions[defined[queue[0]]]
+            queue = queue[1:]
+            for i := fn.BodyStart + 1; i+1 < fn.BodyEnd; i++ {
+                t := toks[i]
+                if t.Kind != tokIdent || toks[i+1].Text != "(" || keywords[t.Text] || funcMacros[t.Text] ||
+                    toks[i-1].Text == "." || toks[i-1].Text == "->" {
+                    continue
+                }
+                if _, ok := defined[t.Text]; ok {
+                    if !visited[t.Text] {
+                        visited[t.Text] = true
+                        queue = append(queue, t.Text)
+                    }
+                    continue
+                }
+                if !asyncSignalSafeFuncs[t.Text] {
+                    report(t, ErrCertSignalHandlerCall, handler, reg.Line+1, t.Text)
+                }
+            }
+        }
+    }
+}
+
+/** ===============================================================
+ *                  T R O J A N  S O U R C E
+ * ================================================================ */
+
+const (
+    regionCode    = 'c'
+    regionComment = '/'
+    regionString  = '"'
+)
+
+func sourceRegions(lines []string) [][]byte {
+    regions := make([][]byte, len(lines))
+    inBlock := false
+    for i, line := range lines {
+        r := make([]byte, len(line))
+        var quote byte
+        for j := 0; j < len(line); j++ {
+            switch {
+            case inBlock:
+                r[j] = regionComment
+                if line[j] == '*' && j+1 < len(line) && line[j+1] == '/' {
+                    r[j+1] = regionComment
+                    j++
+                    inBlock = false
+                }
+            case quote != 0:
+                r[j] = regionString
+                if line[j] == '\\' && j+1 < len(line) {
+                    r[j+1] = regionString
+                    j++
+                } else if line[j] == quote {
+                    quote = 0
+                }
+            case strings.HasPrefix(line[j:], "//"):
+                for ; j < len(line); j++ {
+                    r[j] = regionComment
+                }
+            case strings.HasPrefix(line[j:], "/*"):
+                r[j], r[j+1] = regionComment, regionComment
+                j++
+                inBlock = true
+            case line[j] == '"' || line[j] == '\'':
+                r[j] = regionString
+                quote = line[j]
+            default:
+                r[j] = regionCode
+            }
+        }
+        regions[i] = r
+    }
+    return regions
+}
+
+func homoglyph(ch rune) rune {
+    if ch >= 0xFF21 && ch <= 0xFF3A {
+        return 'A' + ch - 0xFF21
+    }
+    if ch >= 0xFF41 && ch <= 0xFF5A {
+        return 'a' + ch - 0xFF41
+    }
+    return homoglyphs[ch]
+}
+
+func checkUnicode(
+    lines []string,
+    uni *UnicodeConfig,
+    errs *[]StyleError,
+) {
+    report := func(line, col int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: line + 1,
+            Start:   col,
+            Length:  1,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    regions := sourceRegions(lines)
+    for i, line := range lines {
+        confusable := make(map[int]bool)
+        for j := 0; j < len(line); {
+            ch, size := utf8.DecodeRuneInString(line[j:])
+            if regions[i][j] != regionCode || !(ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)) {
+                j += size
+                continue
+            }
+            start := j
+            var glyph, like rune
+            at := -1
+            for j < len(line) {
+                ch, size = utf8.DecodeRuneInString(line[j:])
+                if ch != '_' && !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+                    break
+                }
+                if l := homoglyph(ch); l != 0 {
+                    confusable[j] = true
+                    if at < 0 {
+                        glyph, like, at = ch, l, j
+                    }
+                }
+                j += size
+            }
+            if at >= 0 {
+                report(i, at, ErrHomoglyphIdentifier, line[start:j], glyph, glyph, like)
+            }
+        }
+
+        for j, ch := range line {
+            if ch <= unicode.MaxASCII || confusable[j] {
+                continue
+            }
+            if name, ok := bidiControls[ch]; ok {
+                report(i, j, ErrBidiControlCharacter, ch, name)
+                continue
+            }
+            if name, ok := invisibleChars[ch]; ok {
+                if ch != 0xFEFF || i != 0 || j != 0 {
+                    report(i, j, ErrInvisibleCharacter, ch, name)
+                }
+                continue
+            }
+            switch regions[i][j] {
+            case regionComment:
+                if uni.AllowInComments {
+                    continue
+                }
+            case regionString:
+                if uni.AllowInStrings {
+                    continue
+                }
+            }
+            report(i, j, WarnNonASCIICharacter, ch)
+        }
+    }
+}
+
+/** ===============================================================
+ *                        S E C R E T S
+ * ================================================================ */
+
+type secretPattern struct {
+    name string
+    re   *regexp.Regexp
+}
+
+func secretPatterns(sec *SecretsConfig) ([]secretPattern, []*regexp.Regexp, error) {
+    disabled := make(map[string]bool, len(sec.Disable))
+    for _, name := range sec.Disable {
+        disabled[name] = true
+    }
+    var patterns []secretPattern
+    for _, p := range builtinSecretPatterns {
+        if !disabled[p.name] {
+            patterns = append(patterns, p)
+        }
+    }
+    names := make([]string, 0, len(sec.Patterns))
+    for name := range sec.Patterns {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        re, err := regexp.Compile(sec.Patterns[name])
+        if err != nil {
+            return nil, nil, fmt.Errorf("invalid secrets pattern %q: %v", name, err)
+        }
+        patterns = append(patterns, secretPattern{name, re})
+    }
+    var allow []*regexp.Regexp
+    for _, expr := range sec.Allowlist {
+        re, err := regexp.Compile(expr)
+        if err != nil {
+            return nil, nil, fmt.Errorf("invalid secrets allowlist entry %q: %v", expr, err)
+        }
+        allow = append(allow, re)
+    }
+    return patterns, allow, nil
+}
+
+func shannonEntropy(s string) float64 {
+    counts := make(map[rune]int)
+    n := 0
+    for _, ch := range s {
+        counts[ch]++
+        n++
+    }
+    h := 0.0
+    for _, c := range counts {
+        p := float64(c) / float64(n)
+        h -= p * math.Log2(p)
+    }
+    return h
+}
+
+func looksRandom(word string) bool {
+    var lower, upper, digit bool
+    for _, ch := range word {
+        switch {
+        case ch >= 'a' && ch <= 'z':
+            lower = true
+        case ch >= 'A' && ch <= 'Z':
+            upper = true
+        case ch >= '0' && ch <= '9':
+            digit = true
+        }
+    }
+    return digit && (lower || upper)
+}
+
+func isSecretWordChar(ch rune) bool {
+    return ch < unicode.MaxASCII && (unicode.IsLetter(ch) || unicode.IsDigit(ch) ||
+        ch == '+' || ch == '/' || ch == '=' || ch == '_' || ch == '-')
+}
+
+func checkSecrets(
+    model *sourceModel,
+    sec *SecretsConfig,
+    errs *[]StyleError,
+) {
+    if !sec.Enabled {
+        return
+    }
+    patterns, allow, err := secretPatterns(sec)
+    if err != nil {
+        return
+    }
+    allowed := func(s string) bool {
+        for _, re := range allow {
+            if re.MatchString(s) {
+                return true
+            }
+        }
+        return false
+    }
+
+    scan := func(text string, line, col int, where string) {
+        at := func(off int) (int, int) {
+            nl := strings.LastIndex(text[:off], "\n")
+            if nl < 0 {
+                return line, col + off
+            }
+            return line + strings.Count(text[:off], "\n"), off - nl - 1
+        }
+        report := func(off, length int, code ErrorCode, args ...interface{}) {
+            l, c := at(off)
+            *errs = append(*errs, StyleError{
+                LineNum: l + 1,
+                Start:   c,
+                Length:  length,
+                Message: FormatMessage(code, args...),
+                Level:   FormatErrorLevel(code),
+                Code:    code,
+            })
+        }
+
+        covered := make([]bool, len(text))
+        for _, p := range patterns {
+            for _, m := range p.re.FindAllStringIndex(text, -1) {
+                for k := m[0]; k < m[1]; k++ {
+                    covered[k] = true
+                }
+                switch {
+                case allowed(text[m[0]:m[1]]):
+                case where == "comment" && sec.WarnInComments:
+                    report(m[0], m[1]-m[0], WarnSecretInComment, p.name)
+                default:
+                    report(m[0], m[1]-m[0], ErrHardcodedSecret, p.name, where)
+                }
+            }
+        }
+
+        if sec.EntropyThreshold <= 0 {
+            return
+        }
+        for start := 0; start < len(text); {
+            if !isSecretWordChar(rune(text[start])) {
+                start++
+                continue
+            }
+            end := start
+            for end < len(text) && isSecretWordChar(rune(text[end])) {
+                end++
+            }
+            word := text[start:end]
+            pathLike := word[0] == '/' || start > 0 && text[start-1] == '.' || end < len(text) && text[end] == '.'
+            if len(word) >= sec.EntropyMinLength && !pathLike && !covered[start] && looksRandom(word) && !allowed(word) {
+                if h := shannonEntropy(word); h >= sec.EntropyThreshold {
+                    report(start, len(word), WarnHighEntropySecret, len(word), h, where)
+                }
+            }
+            start = end
+        }
+    }
+
+    strs := model.Tokens
+    for _, d := range model.Directives {
+        if len(d.Tokens) > 1 && d.Tokens[1].Text == "include" {
+            continue
+        }
+        strs = append(strs[:len(strs):len(strs)], d.Tokens...)
+    }
+    for _, t := range strs {
+        if t.Kind == tokString || t.Kind == tokChar {
+            scan(t.Text, t.Line, t.Col, "string literal")
+        }
+    }
+    for _, c := range model.Comments {
+        scan(c.Text, c.Line, c.Col, "comment")
+    }
+}
+
+/** ===============================================================
+ *                 S W I T C H  S T A T E M E N T S
+ * ================================================================ */
+
+func switchLabels(toks []cToken, open int) []int {
+    var labels []int
+    depth := 0
+    for j := open; j < len(toks); j++ {
+        switch toks[j].Text {
+        case "{":
+            depth++
+        case "}":
+            depth--
+        case "case", "default":
+            if depth == 1 {
+                labels = append(labels, j)
+            }
+        case "switch":
+            if j+1 < len(toks) && toks[j+1].Text == "(" {
+                j = matchBracket(toks, j+1)
+                if j+1 < len(toks) && toks[j+1].Text == "{" {
+                    j = matchBracket(toks, j+1)
+                }
+            }
+        }
+        if depth == 0 {
+            break
+        }
+    }
+    return labels
+}
+
+func collectEnums(model *sourceModel, enums map[string][]string) {
+    toks := model.Tokens
+    for i, t := range toks {
+        if t.Text != "enum" {
+            continue
+        }
+        j := i + 1
+        var keys []string
+        if j < len(toks) && toks[j].Kind == tokIdent {
+            keys = append(keys, "enum "+toks[j].Text)
+            j++
+        }
+        if j >= len(toks) || toks[j].Text != "{" {
+            continue
+        }
+        end := matchBracket(toks, j)
+        var names []string
+        for _, part := range splitTopLevel(toks[j+1:end], ",") {
+            if len(part) > 0 && part[0].Kind == tokIdent {
+                names = append(names, part[0].Text)
+            }
+        }
+        if i > 0 && toks[i-1].Text == "typedef" {
+            for k := end + 1; k < len(toks) && toks[k].Text != ";"; k++ {
+                if toks[k].Kind == tokIdent && (toks[k+1].Text == ";" || toks[k+1].Text == ",") {
+                    keys = append(keys, toks[k].Text)
+                }
+            }
+        }
+        for _, key := range keys {
+            enums[key] = names
+        }
+    }
+}
+
+func projectEnums(filename string, model *sourceModel, headerDirs []string) map[string][]string {
+    enums := make(map[string][]string)
+    visited := map[string]bool{filepath.Clean(filename): true}
+    var walk func(name string, m *sourceModel, depth int)
+    walk = func(name string, m *sourceModel, depth int) {
+        collectEnums(m, enums)
+        if depth == 0 {
+            return
+        }
+        for _, d := range m.Directives {
+            if len(d.Tokens) < 3 || d.Tokens[1].Text != "include" || d.Tokens[2].Kind != tokString {
+                continue
+            }
+            inc := strings.Trim(d.Tokens[2].Text, `"`)
+            for _, dir := range append([]string{"."}, headerDirs...) {
+                path := filepath.Clean(filepath.Join(filepath.Dir(name), dir, inc))
+                if visited[path] {
+                    break
+                }
+                if other, err := loadSourceModel(path); err == nil {
+                    visited[path] = true
+                    walk(path, other, depth-1)
+                    break
+                }
+            }
+        }
+    }
+    walk(filename, model, 4)
+    return enums
+}
+
+func switchOperandType(toks []cToken, cond []cToken, at int, params []cParam) string {
+    if len(cond) >= 4 && cond[0].Text == "(" && cond[1].Text == "enum" && cond[3].Text == ")" {
+        return "enum " + cond[2].Text
+    }
+    if len(cond) != 1 || cond[0].Kind != tokIdent {
+        return ""
+    }
+    name := cond[0].Text
+    for j := at - 1; j > 0; j-- {
+        if toks[j].Text != name || j+1 >= len(toks) {
+            continue
+        }
+        switch toks[j+1].Text {
+        case ";", "=", ",", ")", "[":
+        default:
+            continue
+        }
+        prev := toks[j-1]
+        if prev.Kind != tokIdent || keywords[prev.Text] {
+            continue
+        }
+        if j >= 2 && toks[j-2].Text == "enum" {
+            return "enum " + prev.Text
+        }
+        return prev.Text
+    }
+    for _, prm := range params {
+        if prm.Name == name {
+            return strings.TrimPrefix(strings.TrimPrefix(prm.Type, "const "), "volatile ")
+        }
+    }
+    return ""
+}
+
+func checkSwitches(
+    filename string,
+    model *sourceModel,
+    sw *SwitchConfig,
+    misra *MisraConfig,
+    headerDirs []string,
+    errs *[]StyleError,
+) {
+    report := func(t cToken, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: t.Line + 1,
+            Start:   t.Col,
+            Length:  len(t.Text),
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    toks := model.Tokens
+    var enums map[string][]string
+    for _, fn := range model.Functions {
+        if !fn.IsDef || fn.BodyEnd <= fn.BodyStart {
+            continue
+        }
+        for i := fn.BodyStart + 1; i+1 < fn.BodyEnd; i++ {
+            if toks[i].Text != "switch" || toks[i+1].Text != "(" {
+                continue
+            }
+            closing := matchBracket(toks, i+1)
+            if closing+1 >= fn.BodyEnd || toks[closing+1].Text != "{" {
+                continue
+            }
+            t := toks[i]
+            labels := switchLabels(toks, closing+1)
+            defaultPosition := sw.DefaultFirstOrLast && !misra.applies("16.5")
+            cases := 0
+            hasDefault := false
+            covered := make(map[string]bool)
+            for n, at := range labels {
+                l := toks[at]
+                if l.Text == "default" {
+                    hasDefault = true
+                    if defaultPosition && n != 0 && n != len(labels)-1 {
+                        report(l, ErrSwitchDefaultPosition)
+                    }
+                    continue
+                }
+                cases++
+                if toks[at+1].Kind == tokIdent && toks[at+2].Text == ":" {
+                    covered[toks[at+1].Text] = true
+                }
+            }
+
+            switch {
+            case len(labels) == 0:
+                if sw.NoEmpty {
+                    report(t, ErrSwitchEmpty)
+                }
+                continue
+            case cases == 1 && sw.NoSingleCase:
+                report(t, WarnSwitchSingleCase)
+            }
+            if !hasDefault && sw.RequireDefault {
+                report(t, ErrSwitchMissingDefault)
+            }
+
+            if !sw.EnumCoverage || hasDefault && !sw.EnumCoverageWithDefault {
+                continue
+            }
+            key := switchOperandType(toks, toks[i+2:closing], i, fn.Params)
+            if key == "" {
+                continue
+            }
+            if enums == nil {
+                enums = projectEnums(filename, model, headerDirs)
+            }
+            var missing []string
+            for _, name := range enums[key] {
+                if !covered[name] {
+                    missing = append(missing, name)
+                }
+            }
+            if len(missing) > 0 {
+                report(t, WarnSwitchMissingEnumerators, key, strings.Join(missing, ", "))
+            }
+        }
+    }
+}
+
+/** ===============================================================
+ *                    M A C R O  H Y G I E N E
+ * ================================================================ */
+
+func macroParamSafe(body []cToken, k int) bool {
+    prev, next := "", ""
+    if k > 0 {
+        prev = body[k-1].Text
+    }
+    if k+1 < len(body) {
+        next = body[k+1].Text
+    }
+    switch {
+    case prev == "#" || prev == "##" || next == "##":
+        return true
+    case prev == "." || prev == "->" || next == "(":
+        return true
+    case prev == "" && next == "":
+        return true
+    case k > 0 && body[k-1].Kind == tokIdent && prev != "sizeof", k+1 < len(body) && body[k+1].Kind == tokIdent:
+        return true
+    }
+    switch prev {
+    case "(", "[", ",", "{", ";":
+    case "=":
+        return next == ";" || next == "," || next == ")"
+    default:
+        return false
+    }
+    switch next {
+    case ")", "]", ",", "}", ";":
+        return true
+    }
+    return false
+}
+
+func macroEvaluations(body []cToken, params map[string]bool) map[string]int {
+    uses := make(map[string]int)
+    for k := 0; k < len(body); k++ {
+        t := body[k]
+        if (t.Text == "sizeof" || t.Text == "_Alignof" || t.Text == "alignof") && k+1 < len(body) &&
+            body[k+1].Text == "(" {
+            k = matchBracket(body, k+1)
+            continue
+        }
+        if !params[t.Text] || (k > 0 && (body[k-1].Text == "#" || body[k-1].Text == "##")) ||
+            (k+1 < len(body) && body[k+1].Text == "##") {
+            continue
+        }
+        uses[t.Text]++
+    }
+    return uses
+}
+
+func isDoWhileZero(body []cToken) bool {
+    if len(body) < 7 || body[0].Text != "do" || body[1].Text != "{" {
+        return false
+    }
+    end := matchBracket(body, 1)
+    if end >= len(body) {
+        return false
+    }
+    rest := body[end+1:]
+    return len(rest) == 4 && rest[0].Text == "while" && rest[1].Text == "(" && rest[2].Text == "0" && rest[3].Text == ")"
+}
+
+type conditionalBranch struct {
+    group  int
+    branch int
+    guards string
+}
+
+func exclusiveBranches(a, b []conditionalBranch) bool {
+    for _, x := range a {
+        for _, y := range b {
+            if x.group == y.group && x.branch != y.branch {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+func checkMacroHygiene(
+    model *sourceModel,
+    errs *[]StyleError,
+) {
+    report := func(t cToken, length int, code ErrorCode, args ...interface{}) {
+        *errs = append(*errs, StyleError{
+            LineNum: t.Line + 1,
+            Start:   t.Col,
+            Length:  length,
+            Message: FormatMessage(code, args...),
+            Level:   FormatErrorLevel(code),
+            Code:    code,
+        })
+    }
+
+    type definition struct {
+        line int
+        path []conditionalBranch
+    }
+    defined := make(map[string]definition)
+    var path []conditionalBranch
+    groups := 0
+
+    for _, d := range model.Directives {
+        toks := d.Tokens
+        if len(toks) < 2 {
+            continue
+        }
+        switch toks[1].Text {
+        case "if", "ifdef", "ifndef":
+            groups++
+            br := conditionalBranch{group: groups}
+            if toks[1].Text == "ifndef" && len(toks) > 2 {
+                br.guards = toks[2].Text
+            } else if len(toks) > 4 && toks[1].Text == "if" && toks[2].Text == "!" && toks[3].Text == "defined" {
+                br.guards = toks[4].Text
+                if br.guards == "(" && len(toks) > 5 {
+                    br.guards = toks[5].Text
+                }
+            }
+            path = append(path, br)
+            continue
+        case "elif", "else":
+            if len(path) > 0 {
+                path[len(path)-1].branch++
+                path[len(path)-1].guards = ""
+            }
+            continue
+        case "endif":
+            if len(path) > 0 {
+                path = path[:len(path)-1]
+            }
+            continue
+        case "undef":
+            if len(toks) > 2 {
+                delete(defined, toks[2].Text)
+            }
+            continue
+        case "define":
+        default:
+            continue
+        }
+        if len(toks) < 3 || toks[2].Kind != tokIdent {
+            continue
+        }
+        name := toks[2]
+
+        guarded := false
+        for _, br := range path {
+            guarded = guarded || br.guards == name.Text
+        }
+        if prev, ok := defined[name.Text]; ok && !guarded && !exclusiveBranches(prev.path, path) {
+            report(name, len(name.Text), ErrMacroRedefined, name.Text, prev.line+1)
+        }
+        defined[name.Text] = definition{line: d.Line, path: append([]conditionalBranch(nil), path...)}
+
+        depth, unopened := 0, 0
+        for _, t := range toks[3:] {
+            switch t.Text {
+            case "(":
+                depth++
+            case ")":
+                if depth == 0 {
+                    unopened++
+                } else {
+                    depth--
+                }
+            }
+        }
+        if depth != 0 || unopened != 0 {
+            report(name, len(name.Text), ErrMacroUnbalancedParens, name.Text, d.Line+1, d.EndLine+1)
+            continue
+        }
+
+        if len(toks) < 4 || toks[3].Text != "(" || !tokensTouch(name, toks[3]) {
+            continue
+        }
+        closing := matchBracket(toks, 3)
+        params := make(map[string]bool)
+        for _, part := range splitTopLevel(toks[4:closing], ",") {
+            switch {
+            case len(part) == 1 && part[0].Text == "...":
+                params["__VA_ARGS__"] = true
+            case len(part) >= 1 && part[0].Kind == tokIdent:
+                params[part[0].Text] = true
+            }
+        }
+        body := toks[closing+1:]
+        if len(body) == 0 {
+            continue
+        }
+
+        statements := body[0].Text == "{"
+        for _, part := range splitTopLevel(body, ";")[1:] {
+            statements = statements || len(part) > 0
+        }
+        switch {
+        case declSpecWords[body[0].Text] || baseTypeWords[body[0].Text] || body[0].Text == "struct" ||
+            body[0].Text == "union" || body[0].Text == "enum":
+        case isDoWhileZero(body[:len(body)-1]) && body[len(body)-1].Text == ";":
+            report(body[len(body)-1], 1, ErrMacroTrailingSemicolon, name.Text)
+        case statements:
+            report(name, len(name.Text), ErrMacroNotDoWhile, name.Text)
+        case body[len(body)-1].Text == ";":
+            report(body[len(body)-1], 1, ErrMacroTrailingSemicolon, name.Text)
+        }
+
+        if len(body) > 1 {
+            for k, t := range body {
+                if params[t.Text] && t.Text != "__VA_ARGS__" && !macroParamSafe(body, k) {
+                    report(t, len(t.Text), ErrMacroParamNotParenthesized, t.Text, name.Text)
+                }
+            }
+        }
+        uses := macroEvaluations(body, params)
+        for _, part := range splitTopLevel(toks[4:closing], ",") {
+            if len(part) == 1 && uses[part[0].Text] > 1 {
+                report(name, len(name.Text), WarnMacroParamEvaluatedTwice, part[0].Text, name.Text, uses[part[0].Text])
+            }
+        }
+    }
+}
+
+/** ===============================================================
+ *               P R E P R O C E S S O R  L I N E S
+ * ================================================================ */
+
+type logicalSegment struct {
+    Off  int
+    Line int
+    Col  int
+}
+
+type logicalLine struct {
+    Text string
+    Code string
+    Segs []logicalSegment
+}
+
+func logicalLines(lines []string, model *sourceModel) map[int]*logicalLine {
+    logical := make(map[int]*logicalLine)
+    for _, d := range model.Directives {
+        if d.EndLine == d.Line {
+            continue
+        }
+        lg := &logicalLine{}
+        var sb strings.Builder
+        for l := d.Line; l <= d.EndLine && l < len(lines); l++ {
+            text := strings.TrimRight(lines[l], " \t\r")
+            if l < d.EndLine {
+                text = strings.TrimRight(strings.TrimSuffix(text, "\\"), " \t")
+            }
+            col := 0
+            if l > d.Line {
+                col = len(text) - len(strings.TrimLeft(text, " \t"))
+                text = text[col:]
+                sb.WriteByte(' ')
+            }
+            lg.Segs = append(lg.Segs, logicalSegment{Off: sb.Len(), Line: l, Col: col})
+            sb.WriteString(text)
+        }
+        lg.Text = sb.String()
+        lg.Code = reLogicalComment.ReplaceAllStringFunc(lg.Text, func(c string) string {
+            return strings.Repeat(" ", len(c))
+        })
+        maskStringLiterals(&lg.Code, ' ')
+        maskCharLiterals(&lg.Code, ' ')
+        logical[d.Line] = lg
+    }
+    return logical
+}
+
+func (lg *logicalLine) pos(off int) (int, int) {
+    seg := lg.Segs[0]
+    for _, s := range lg.Segs {
+        if s.Off <= off {
+            seg = s
+        }
+    }
+    return seg.Line, seg.Col + off - seg.Off
+}
+
+func (lg *logicalLine) remap(errs []StyleError) []StyleError {
+    for k := range errs {
+        if errs[k].Start < 0 {
+            continue
+        }
+        line, col := lg.pos(errs[k].Start)
+        errs[k].LineNum, errs[k].Start = line+1, col
+    }
+    return errs
+}
+
+func directiveContinuations(model *sourceModel) map[int]bool {
+    cont := make(map[int]bool)
+    for _, d := range model.Directives {
+        for l := d.Line + 1; l <= d.EndLine; l++ {
+            cont[l] = true
+        }
+    }
+    return cont
+}
+
+func trimContinuation(code string) string {
+    trimmed := strings.TrimRight(code, " \t\r")
+    if !strings.HasSuffix(trimmed, "\\") {
+        return code
+    }
+    return strings.TrimRight(strings.TrimSuffix(trimmed, "\\"), " \t")
+}
+
+func checkBackslashAlignment(
+    lines []string,
+    model *sourceModel,
+    ind *IndentConfig,
+    errs *[]StyleError,
+) {
+    for _, d := range model.Directives {
+        if d.EndLine == d.Line {
+            continue
+        }
+        cols := make(map[int]int)
+        want := 0
+        for l := d.Line; l < d.EndLine && l < len(lines); l++ {
+            text := strings.TrimRight(lines[l], " \t\r")
+            idx := len(text) - 1
+            cols[l] = displayColumn(text, idx, ind.TabWidth)
+            if cols[l] > want {
+                want = cols[l]
+            }
+        }
+        for l := d.Line; l < d.EndLine && l < len(lines); l++ {
+            if cols[l] == want {
+                continue
+            }
+            *errs = append(*errs, StyleError{
+                LineNum: l + 1,
+                Start:   len(strings.TrimRight(lines[l], " \t\r")) - 1,
+                Length:  1,
+                Message: FormatMessage(ErrBackslashNotAligned, cols[l], want),
+                Level:   FormatErrorLevel(ErrBackslashNotAligned),
+                Code:    ErrBackslashNotAligned,
+            })
+        }
+    }
+}
+
+/** ===============================================================
+ *                    M A G I C  N U M B E R S
+ * ================================================================ */
+
+func numberValue(text string) (float64, bool) {
+    s := strings.ToLower(strings.ReplaceAll(text, "'", ""))
+    neg := strings.HasPrefix(s, "-")
+    s = strings.TrimPrefix(s, "-")
+    hex := strings.HasPrefix(s, "0x")
+    isFloat := (hex && strings.Contains(s, "p")) || (!hex && strings.ContainsAny(s, ".e"))
+
+    var v float64
+    if isFloat {
+        f, err := strconv.ParseFloat(strings.TrimRight(s, "fl"), 64)
+        if err != nil {
+            return 0, false
+        }
+        v = f
+    } else {
+        u, err := strconv.ParseUint(strings.TrimRight(s, "ul"), 0, 64)
+        if err != nil {
+            return 0, false
+        }
+        v = float64(u)
+    }
+    if neg {
+        v = -v
+    }
+    return v, true
+}
+
+func isPowerOfTwoLiteral(text string, v float64) bool {
+    if v < 2 || v != math.Trunc(v) || v > math.MaxUint64 {
+        return false
+    }
+    if u := uint64(v); u&(u-1) == 0 {
+        s := strings.ToLower(text)
+        return strings.HasPrefix(s, "0x") || !strings.ContainsAny(s, ".e")
+    }
+    return false
+}
+
+func magicNumberContext(toks []cToken, i int) (int, int) {
+    depth := 0
+    for j := i - 1; j >= 0; j-- {
+        switch toks[j].Text {
+        case "}":
+            depth++
+        case "{":
+            if depth > 0 {
+                depth--
+                continue
+            }
+            if j > 0 && (toks[j-1].Text == "=" || toks[j-1].Text == "," || toks[j-1].Text == "{") {
+                continue
+            }
+            return j + 1, j
+        case ";":
+            if depth == 0 {
+                return j + 1, -1
+            }
+        }
+    }
+    return 0, -1
+}
+
+func isConversionBase(toks []cToken, i int) bool {
+    if i+1 >= len(toks) || toks[i+1].Text != ")" {
+        return false
+    }
+    commas, depth := 0, 0
+    for j := i - 1; j > 0; j-- {
+        switch toks[j].Text {
+        case ")", "]":
+            depth++
+        case "[":
+            depth--
+        case ",":
+            if depth == 0 {
+                commas++
+            }
+        case "(":
+            if depth == 0 {
+                return commas == 2 && conversionBaseFuncs[toks[j-1].Text]
+            }
+            depth--
+        }
+    }
+    return false
+}
+
+func checkMagicNumbers(
+    model *sourceModel,
+    mn *MagicNumberConfig,
+    errs *[]StyleError,
+) {
+    if !mn.Enabled {
+        return
+    }
+    allowed := make(map[float64]bool, len(mn.Allow))
+    for _, a := range mn.Allow {
+        if v, ok := numberValue(a); ok {
+            allowed[v] = true
+        }
+    }
+
+    toks := model.Tokens
+    owner := make([]string, len(toks))
+    for _, fn := range model.Functions {
+        if fn.IsDef && fn.BodyEnd > fn.BodyStart {
+            for k := fn.BodyStart; k <= fn.BodyEnd && k < len(toks); k++ {
+                owner[k] = fn.Name
+            }
+        }
+    }
+
+    for i, t := range toks {
+        if t.Kind != tokNumber {
+            continue
+        }
+        first, text := t, t.Text
+        if i > 0 && toks[i-1].Text == "-" && (i < 2 || !isOperandEnd(toks[i-2])) {
+            first, text = toks[i-1], "-"+t.Text
+        }
+        v, ok := numberValue(text)
+        if !ok || allowed[v] || (mn.AllowPowersOfTwo && isPowerOfTwoLiteral(t.Text, v)) {
+            continue
+        }
+        if i > 0 && mn.AllowShiftCounts {
+            switch toks[i-1].Text {
+            case "<<", ">>", "<<=", ">>=":
+                continue
+            }
+        }
+
+        start, brace := magicNumberContext(toks, i)
+        stmt := toks[start:i]
+        switch {
+        case brace > 1 && (toks[brace-1].Text == "enum" || toks[brace-2].Text == "enum"):
+            continue
+        case hasWord(stmt, "const") && hasWord(stmt, "=", "["):
+            continue
+        case isConversionBase(toks, i):
+            continue
+        case i > 1 && toks[i-1].Text == ":" && toks[i-2].Kind == tokIdent && i+1 < len(toks) &&
+            (toks[i+1].Text == ";" || toks[i+1].Text == ",") && !hasWord(stmt, "?", "case"):
+            continue
+        }
+
+        where := "at file scope"
+        if owner[i] != "" {
+            where = fmt.Sprintf("in function '%s'", owner[i])
+        }
+        *errs = append(*errs, StyleError{
+            LineNum: first.Line + 1,
+            Start:   first.Col,
+            Length:  t.Col + len(t.Text) - first.Col,
+            Message: FormatMessage(WarnMagicNumberDetected, text, where),
+            Level:   FormatErrorLevel(WarnMagicNumberDetected),
+            Code:    WarnMagicNumberDetected,
+        })
+    }
+}
+
+/** ===============================================================
+ *                  C L I  F U N C T I O N S
+ * ================================================================ */
+func printContext(lines []string, err StyleError) {
+
+    start := err.LineNum - 2
+
+    if start < 0 {
+        start = 0
+    }
+
+    end := err.LineNum
+
+    if end >= len(lines) {
+        end = len(lines) - 1
+    }
+
+    for i := start; i <= end; i++ {
+        line := lines[i]
+        fmt.Printf("%s%3d%s %s|%s ", LineNumCol, i+1, Reset, PipeCol, Reset)
+
+        if i == err.LineNum-1 {
+            fmt.Println(highlightError(line, err.Start, err.Length))
+        } else {
+            fmt.Println(highlightLine(line))
+        }
+    }
+}
+
+func highlightError(line string, start, length int) string {
+
+    n := len(line)
+
+    if start < 0 {
+        start = 0
+    } else if start > n {
+        start = n
+    }
+    for start > 0 && start < n && !utf8.RuneStart(line[start]) {
+        start--
+    }
+
+    if length < 0 {
+        length = 0
+    }
+    end := start + length
+    if end > n {
+        end = n
     }
-    if start+length > n {
-        length = n - start
+    for end < n && !utf8.RuneStart(line[end]) {
+        end++
     }
 
-    before := string(r[:start])
-    errPart := string(r[start : start+length])
-    after := string(r[start+length:])
+    before := line[:start]
+    errPart := line[start:end]
+    after := line[end:]
 
     return highlightLine(before) + ErrorBg + Operator + errPart + Reset + highlightLine(after)
 }